@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Command to manage the cached remote workflows",
+	Long: `Remote workflows used by gen are cached in the bootengine config dir, keyed by url and revision,
+so that they can be reused offline. Use gen --refresh to fetch them again.`,
+}
+
+func init() {
+	RootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+// cacheCleanCmd represents the cache clean command
+var cacheCleanCmd = &cobra.Command{
+	Use:           "clean",
+	Short:         "Remove every cached remote workflow.",
+	Long:          `Remove every cached remote workflow, they will be fetched again on their next use.`,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := parser.NewCache(false)
+		if err != nil {
+			return err
+		}
+		entries, err := cache.List()
+		if err != nil {
+			return err
+		}
+		if err = cache.Clean(); err != nil {
+			return err
+		}
+		log.Infof("removed %d cached workflow(s)", len(entries))
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheCleanCmd)
+}
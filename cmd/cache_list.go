@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+// cacheListCmd represents the cache list command
+var cacheListCmd = &cobra.Command{
+	Use:           "list",
+	Aliases:       []string{"ls"},
+	Short:         "List the cached remote workflows.",
+	Long:          `List the cached remote workflows, displaying their source, requested ref, checked out revision and fetch date.`,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := parser.NewCache(false)
+		if err != nil {
+			return err
+		}
+		entries, err := cache.List()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "no cached workflow")
+			return nil
+		}
+
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("240"))).
+			Headers("source", "ref", "revision", "fetched at")
+		for _, entry := range entries {
+			revision := entry.Revision
+			if len(revision) > 12 {
+				revision = revision[:12]
+			}
+			t.Row(entry.Source, entry.Ref, revision, entry.FetchedAt.Format(time.DateTime))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), t.Render())
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+}
@@ -1,47 +1,92 @@
 package cmd
 
 import (
-	"cuelang.org/go/cue/cuecontext"
+	"context"
+	"encoding/json"
+	"fmt"
+
 	"github.com/bootengine/boot/internal/helper"
 	"github.com/bootengine/boot/internal/parser"
+	"github.com/bootengine/boot/internal/usecase"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
 )
 
 type checkCmdsFlags struct {
 	filename string
+	format   string
 }
 
 var checkFlags checkCmdsFlags
 
 // checkCmd represents the check command
 var checkCmd = &cobra.Command{
-	Use:           "check",
-	Short:         "Check that the given file is valid.",
-	Long:          `Check that the given file is a valid boot workflow. It will not check that selected module are installed, it will just check that it`,
+	Use:   "check",
+	Short: "Check that the given file is valid.",
+	Long: `Check that the given file and the workflows it includes are valid boot workflows. The file can be a url, like for gen.
+It will not check that selected module are installed, it will just check that they follow the workflow format and suggest
+the installed module a misspelled module name looks like. Every problem is reported with its position in the file.`,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := cuecontext.New()
-		p := parser.NewParser()
-		cueValue, err := helper.CueUnmarshalFile(ctx, checkFlags.filename)
+		if checkFlags.format != "text" && checkFlags.format != "json" {
+			return fmt.Errorf("unsupported format %q, expected text or json", checkFlags.format)
+		}
+
+		p := parser.NewParser().WithModules(installedModules())
+		defer p.Cleanup()
+		// remote workflows are fetched, then every workflow is checked along with the ones it includes
+		_, err := p.Parse(checkFlags.filename)
+
+		problems := parser.CheckErrors{}
 		if err != nil {
-			return err
+			problems = parser.AsCheckErrors(checkFlags.filename, err)
 		}
 
-		if err = p.Check(ctx, *cueValue); err != nil {
-			return err
+		if checkFlags.format == "json" {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err = enc.Encode(problems); err != nil {
+				return err
+			}
+		} else {
+			for _, problem := range problems {
+				fmt.Fprintln(cmd.OutOrStdout(), problem.Error())
+			}
 		}
 
-		// TODO: log success
-		log.Info("everything is fine !")
+		if len(problems) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d problem(s) found in %s", len(problems), checkFlags.filename)
+		}
+		if checkFlags.format == "text" {
+			log.Info("everything is fine !")
+		}
 		return nil
 	},
 }
 
+// installedModules returns the names of the installed modules, none when the module database cannot be read.
+func installedModules() []string {
+	var names []string
+	err := helper.WithModuleUsecase(func(ctx context.Context, use *usecase.ModuleUsecase) error {
+		modules, err := use.ListModules(ctx)
+		for _, mod := range modules {
+			names = append(names, mod.Name)
+		}
+		return err
+	})
+	if err != nil {
+		log.Debug("installed modules are not suggested", "err", err)
+	}
+	return names
+}
+
 func init() {
 	RootCmd.AddCommand(checkCmd)
 
 	checkCmd.Flags().StringVarP(&checkFlags.filename, "filename", "f", "", `the path to the config file you want to check.`)
-	checkCmd.MarkFlagFilename("filename", []string{string(helper.JSON), string(helper.YAML), string(helper.YML)}...)
+	checkCmd.Flags().StringVar(&checkFlags.format, "format", "text", "output format of the problems, text or json (for editor integrations).")
+	checkCmd.MarkFlagFilename("filename", helper.WorkflowFileTypes...)
 	checkCmd.MarkFlagRequired("filename")
+	checkCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
 }
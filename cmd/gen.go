@@ -2,19 +2,25 @@ package cmd
 
 import (
 	"context"
-	"errors"
-	"regexp"
-	"sync"
 
 	"github.com/bootengine/boot/internal/helper"
 	"github.com/bootengine/boot/internal/parser"
 	"github.com/bootengine/boot/internal/runner"
 	"github.com/bootengine/boot/internal/usecase"
+	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
 )
 
 type genCmdFlags struct {
-	pathOrURL string
+	pathOrURL     string
+	sets          []string
+	answersFile   string
+	noInput       bool
+	dryRun        bool
+	keepOnFailure bool
+	resume        bool
+	refresh       bool
+	jobs          int
 }
 
 var genFlags genCmdFlags
@@ -26,51 +32,57 @@ var genCmd = &cobra.Command{
 	Long:          `Generate a new project from a config file. This file can be either on your local computer or it can be a repository.`,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var once sync.Once
-		onceFunc := func() {
-			defer func() {
-				err := cleanup()
-				if err != nil {
-					// panic ?
-				}
-			}()
-		}
 		return helper.WithModuleUsecase(func(ctx context.Context, use *usecase.ModuleUsecase) error {
-			reg := regexp.MustCompile("^(http|https)://.*$")
-			if reg.Match([]byte(genFlags.pathOrURL)) {
-				// dl repo in tmp folder
-				// compute path to tmp/repo/whatever.[yaml|json|toml|...]
-				// defer cleanup
-				once.Do(onceFunc)
-			}
-			work, err := parser.NewParser().Parse(genFlags.pathOrURL)
+			cache, err := parser.NewCache(genFlags.refresh)
 			if err != nil {
 				return err
 			}
+			p := parser.NewParser().WithCache(cache)
+			defer func() {
+				if err := p.Cleanup(); err != nil {
+					log.Warnf("failed to remove downloaded workflow: %s", err)
+				}
+			}()
 
-			worker := runner.NewRunner(use, *work)
-			err = worker.Run()
+			work, err := p.Parse(genFlags.pathOrURL)
 			if err != nil {
-				if errors.Is(err, runner.NoKeepGoingError(false)) {
-					once.Do(onceFunc)
-				}
 				return err
 			}
-			return nil
+
+			worker := runner.NewRunner(ctx, use, *work, runner.Options{
+				Sets:          genFlags.sets,
+				AnswersFile:   genFlags.answersFile,
+				NoInput:       genFlags.noInput,
+				DryRun:        genFlags.dryRun,
+				KeepOnFailure: genFlags.keepOnFailure,
+				WorkflowFile:  genFlags.pathOrURL,
+				Resume:        genFlags.resume,
+				Jobs:          genFlags.jobs,
+			})
+			return worker.Run()
 		})
 	},
 }
 
-func cleanup() error {
-	return nil
-}
-
 func init() {
 	RootCmd.AddCommand(genCmd)
 
-	genCmd.Flags().StringVarP(&genFlags.pathOrURL, "file", "f", "", `config file for the generation process, can be either a repo url or a local path.
-If it's a repo url, the repo will be downloaded in a tmp dir and removed afterward.
-		`)
+	genCmd.Flags().StringVarP(&genFlags.pathOrURL, "file", "f", "", `config file for the generation process, can be either a local path, an http(s) url
+or a git reference (git+https://host/org/repo//path/workflow.yaml@ref).
+A remote workflow is cached in the bootengine config dir, see the cache command.`)
+	genCmd.Flags().StringArrayVar(&genFlags.sets, "set", nil, `value of a var, as name=value. Can be repeated.
+Takes precedence over BOOT_VAR_<NAME> environment variables and the answers file.`)
+	genCmd.Flags().StringVarP(&genFlags.answersFile, "answers", "a", "", "path to a yaml or json file containing the values of vars.")
+	genCmd.Flags().BoolVar(&genFlags.noInput, "no-input", false, "never prompt, fail if a required var has no value.")
+	genCmd.Flags().BoolVar(&genFlags.dryRun, "dry-run", false, "print the steps, commands and files of the generation without writing nor executing anything.")
+	genCmd.Flags().BoolVar(&genFlags.keepOnFailure, "keep-on-failure", false, "keep what has been created when the generation fails, instead of offering to roll it back.")
+	genCmd.Flags().BoolVar(&genFlags.resume, "resume", false, "continue an interrupted generation of the same workflow in the same directory, from its first unfinished step. Passwords are not saved, they are prompted again or given with --set.")
+	genCmd.Flags().IntVarP(&genFlags.jobs, "jobs", "j", 1, `number of steps run at the same time. Steps with needs run once the steps they need are done,
+the others once every step declared before them are done.`)
+	genCmd.Flags().BoolVar(&genFlags.refresh, "refresh", false, "fetch a remote workflow again instead of using its cached copy.")
+	genCmd.MarkFlagsMutuallyExclusive("resume", "dry-run")
 
 	genCmd.MarkFlagRequired("file")
+	genCmd.MarkFlagFilename("file", helper.WorkflowFileTypes...)
+	genCmd.MarkFlagFilename("answers", []string{string(helper.JSON), string(helper.YAML), string(helper.YML)}...)
 }
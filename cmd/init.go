@@ -8,6 +8,7 @@ import (
 	//"cuelang.org/go/encoding/yaml"
 	"github.com/bootengine/boot/internal/helper"
 	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/parser"
 	"github.com/spf13/cobra"
 )
 
@@ -25,14 +26,7 @@ var initCmd = &cobra.Command{
 	Long:          `Init will help you generate a default boot workflow file into the output filename given an output type [json, yaml]`,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		workflow := newDefaultWorkflow()
-
-		gen := model.GeneratingWorkflow{
-			Config:       workflow.Config,
-			Vars:         workflow.Vars,
-			Steps:        workflow.Steps,
-			FolderStruct: workflow.FolderStruct.Convert(),
-		}
+		gen := newDefaultWorkflow().Convert()
 
 		var (
 			marshaled []byte
@@ -63,6 +57,7 @@ func init() {
 
 func newDefaultWorkflow() model.Workflow {
 	return model.Workflow{
+		Version: parser.CurrentVersion,
 		Config: model.Config{
 			CreateRoot: true,
 		},
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/lint"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/bootengine/boot/internal/usecase"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+type lintCmdFlags struct {
+	filename string
+	format   string
+}
+
+var lintFlags lintCmdFlags
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check that the given file can be generated with the installed modules.",
+	Long: `Check that the given file and the workflows it includes are valid, then that it can be generated: step modules
+are installed and capable of their action, template engines and files exist, templates only use declared vars and
+step names are unique.`,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if lintFlags.format != "text" && lintFlags.format != "json" {
+			return fmt.Errorf("unsupported format %q, expected text or json", lintFlags.format)
+		}
+
+		return helper.WithModuleUsecase(func(ctx context.Context, use *usecase.ModuleUsecase) error {
+			p := parser.NewParser()
+			defer p.Cleanup()
+			work, err := p.Parse(lintFlags.filename)
+			if err != nil {
+				return err
+			}
+
+			modules, err := use.ListModules(ctx)
+			if err != nil {
+				return err
+			}
+			problems := lint.Lint(*work, modules)
+			if problems == nil {
+				problems = []lint.Problem{}
+			}
+
+			if lintFlags.format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(problems); err != nil {
+					return err
+				}
+			} else {
+				for _, problem := range problems {
+					fmt.Fprintln(cmd.OutOrStdout(), problem)
+				}
+			}
+
+			if lint.HasErrors(problems) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d problem(s) found in %s", len(problems), lintFlags.filename)
+			}
+			if lintFlags.format == "text" {
+				log.Info("everything is fine !")
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintFlags.filename, "filename", "f", "", `the path to the config file you want to lint.`)
+	lintCmd.Flags().StringVar(&lintFlags.format, "format", "text", "output format of the problems, text or json (for editor integrations).")
+	lintCmd.MarkFlagFilename("filename", helper.WorkflowFileTypes...)
+	lintCmd.MarkFlagRequired("filename")
+	lintCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+type migrateCmdFlags struct {
+	filename string
+	write    bool
+}
+
+var migrateFlags migrateCmdFlags
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the given file to the current workflow format.",
+	Long: `Upgrade the given workflow file to the current version of the workflow format.
+The upgraded file is printed, use --write to replace the file: the original one is kept next to it with a .bak extension.
+Comments of yaml files are kept. Toml and cue files cannot be rewritten: the changes to make are listed instead.`,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		migration, err := parser.MigrateFile(migrateFlags.filename)
+		if err != nil {
+			return err
+		}
+		if len(migration.Changes) == 0 {
+			log.Info("already up to date", "filename", migration.Filename, "version", migration.To)
+			return nil
+		}
+
+		for _, change := range migration.Changes {
+			log.Info(change)
+		}
+		if migration.Content == nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s cannot be rewritten, make the changes above by hand", migration.Filename)
+		}
+		if !migrateFlags.write {
+			fmt.Fprint(cmd.OutOrStdout(), string(migration.Content))
+			return nil
+		}
+
+		info, err := os.Stat(migration.Filename)
+		if err != nil {
+			return err
+		}
+		original, err := os.ReadFile(migration.Filename)
+		if err != nil {
+			return err
+		}
+		backup := migration.Filename + ".bak"
+		if err = os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err = os.WriteFile(migration.Filename, migration.Content, info.Mode().Perm()); err != nil {
+			return err
+		}
+		log.Info("workflow migrated", "filename", migration.Filename, "backup", backup, "from", migration.From, "to", migration.To)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVarP(&migrateFlags.filename, "filename", "f", "", `the path to the workflow file you want to upgrade.`)
+	migrateCmd.Flags().BoolVarP(&migrateFlags.write, "write", "w", false, "replace the file with the upgraded one, after a backup to <filename>.bak.")
+	migrateCmd.MarkFlagFilename("filename", helper.WorkflowFileTypes...)
+	migrateCmd.MarkFlagRequired("filename")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+type schemaCmdFlags struct {
+	format string
+}
+
+var schemaFlags schemaCmdFlags
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the schema of workflow files.",
+	Long: `Print the schema workflow files are checked against, either as cue or as a JSON Schema (draft 2020-12).
+The JSON Schema lets editors complete and validate workflow files, e.g. with a yaml-language-server comment:
+
+# yaml-language-server: $schema=<path of the exported schema>`,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch schemaFlags.format {
+		case "cue":
+			fmt.Fprint(cmd.OutOrStdout(), parser.Schema())
+			return nil
+		case "jsonschema":
+			schema, err := parser.JSONSchema()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+			return nil
+		}
+		return fmt.Errorf("unsupported format %q, expected cue or jsonschema", schemaFlags.format)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVar(&schemaFlags.format, "format", "cue", "format of the schema, cue or jsonschema.")
+	schemaCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"cue", "jsonschema"}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,87 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// toFloat converts any numeric value to a float64.
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// equal checks that a and b are equal. Numbers are compared by value, anything else by its string form.
+func equal(a, b any) bool {
+	fa, aIsNumber := toFloat(a)
+	fb, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		return fa == fb
+	}
+	return toString(a) == toString(b)
+}
+
+// contains checks that elem is in container: an element of a list, or a substring of a string.
+func contains(container, elem any) (bool, error) {
+	switch container := container.(type) {
+	case []any:
+		for _, v := range container {
+			if equal(v, elem) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case []string:
+		for _, v := range container {
+			if equal(v, elem) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		return strings.Contains(container, toString(elem)), nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("can't look for a value in %v", container)
+}
+
+func compare(op string, left, right any) (any, error) {
+	switch op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	case "not in":
+		res, err := contains(right, left)
+		return !res, err
+	}
+
+	fl, lok := toFloat(left)
+	fr, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s only compares numbers, got %v and %v", op, left, right)
+	}
+	switch op {
+	case "<":
+		return fl < fr, nil
+	case "<=":
+		return fl <= fr, nil
+	case ">":
+		return fl > fr, nil
+	case ">=":
+		return fl >= fr, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
@@ -0,0 +1,183 @@
+// Package expr contains the small expression language used in workflow files.
+//
+// Expressions are written between double curly braces, in a jinja-like way:
+//
+//	github.com/{{ author_github_name }}/{{ project_name | kebab }}
+//	Copyright {{ now("2006") }} {{ owner | upper }}
+//
+// A value is either a var name (dots access nested values), a quoted string, a number,
+// true, false, nil or a function call. Values can be piped into functions with `|`, the piped value
+// becoming the first argument of the function.
+package expr
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// An UndefinedError occurs when an expression references vars that have no value.
+type UndefinedError struct {
+	Names []string
+}
+
+// Error implements the [error] interface.
+func (u UndefinedError) Error() string {
+	return fmt.Sprintf("undefined vars: %s", strings.Join(u.Names, ", "))
+}
+
+// A SyntaxError occurs when an expression cannot be parsed.
+type SyntaxError struct {
+	Expr string
+	err  error
+}
+
+// Error implements the [error] interface.
+func (s SyntaxError) Error() string {
+	return fmt.Sprintf("invalid expression %q: %s", s.Expr, s.err)
+}
+
+func (s SyntaxError) Unwrap() error {
+	return s.err
+}
+
+const (
+	openDelim  = "{{"
+	closeDelim = "}}"
+)
+
+// HasExpr checks that s contains at least one expression.
+func HasExpr(s string) bool {
+	return strings.Contains(s, openDelim)
+}
+
+// Render replaces every expression of tmpl with its value computed from values.
+// Every undefined var is reported at once in an [UndefinedError].
+func Render(tmpl string, values map[string]any) (string, error) {
+	var (
+		sb        strings.Builder
+		undefined []string
+		rest      = tmpl
+	)
+	for {
+		start := strings.Index(rest, openDelim)
+		if start < 0 {
+			sb.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest[start:], closeDelim)
+		if end < 0 {
+			return "", SyntaxError{Expr: tmpl, err: fmt.Errorf("missing closing %s", closeDelim)}
+		}
+		sb.WriteString(rest[:start])
+
+		src := rest[start+len(openDelim) : start+end]
+		res, err := Eval(src, values)
+		if u, ok := err.(UndefinedError); ok {
+			undefined = append(undefined, u.Names...)
+		} else if err != nil {
+			return "", err
+		}
+		sb.WriteString(toString(res))
+		rest = rest[start+end+len(closeDelim):]
+	}
+
+	if len(undefined) > 0 {
+		sort.Strings(undefined)
+		return "", UndefinedError{Names: slices.Compact(undefined)}
+	}
+	return sb.String(), nil
+}
+
+// Eval computes the value of a single expression, written without curly braces.
+//
+// On top of values and functions, an expression can compare values with ==, !=, <, <=, >, >=,
+// check membership with in and not in, and combine conditions with && (and), || (or) and ! (not):
+//
+//	use_docker && frontend in ["react", "vue"]
+func Eval(src string, values map[string]any) (any, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, SyntaxError{Expr: src, err: err}
+	}
+	p := &exprParser{tokens: tokens, values: values}
+	res, err := p.parseExpression()
+	if err != nil {
+		return nil, SyntaxError{Expr: src, err: err}
+	}
+	if len(p.undefined) > 0 {
+		return nil, UndefinedError{Names: p.undefined}
+	}
+	return res, nil
+}
+
+// EvalBool computes the value of a condition, see [Truthy].
+func EvalBool(src string, values map[string]any) (bool, error) {
+	res, err := Eval(src, values)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(res), nil
+}
+
+// Truthy tells if v is considered true in a condition:
+// false, nil, zero numbers, empty strings, empty lists and empty maps are false, anything else is true.
+func Truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	}
+	if f, ok := toFloat(v); ok {
+		return f != 0
+	}
+	return true
+}
+
+// Lookup retrieves the value of name in values. A name is either a key of values,
+// or a dot separated path to a value in nested maps.
+func Lookup(values map[string]any, name string) (any, bool) {
+	if v, ok := values[name]; ok {
+		return v, true
+	}
+	var current any = values
+	for _, part := range strings.Split(name, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case []any:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			parts[i] = toString(elem)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,221 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/bootengine/boot/internal/expr"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_Render(t *testing.T) {
+	values := map[string]any{
+		"project_name":       "My Super Project",
+		"author_github_name": "bootengine",
+		"linters":            []string{"eslint", "prettier"},
+		"api": map[string]any{
+			"port": 8080,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "no expression",
+			input:    "github.com/bootengine/boot",
+			expected: "github.com/bootengine/boot",
+		},
+		{
+			name:     "simple vars",
+			input:    "github.com/{{ author_github_name }}/{{project_name}}",
+			expected: "github.com/bootengine/My Super Project",
+		},
+		{
+			name:     "helpers",
+			input:    "{{ project_name | snake }} {{ project_name | kebab }} {{ project_name | camel }} {{ project_name | pascal }}",
+			expected: "my_super_project my-super-project mySuperProject MySuperProject",
+		},
+		{
+			name:     "helpers - chained with arguments",
+			input:    `{{ project_name | kebab | replace("-", ".") | upper }}`,
+			expected: "MY.SUPER.PROJECT",
+		},
+		{
+			name:     "function call",
+			input:    `{{ lower("HTTPServer") }} {{ snake("HTTPServerName") }}`,
+			expected: "httpserver http_server_name",
+		},
+		{
+			name:     "nested value and list",
+			input:    `:{{ api.port }} {{ linters | join(" ") }}`,
+			expected: ":8080 eslint prettier",
+		},
+		{
+			name:        "undefined vars",
+			input:       "{{ owner }}/{{ project_name }}/{{ repo | kebab }}/{{ owner }}",
+			expectedErr: "undefined vars: owner, repo",
+		},
+		{
+			name:        "unknown function",
+			input:       "{{ project_name | shout }}",
+			expectedErr: `invalid expression " project_name | shout ": unknown function shout`,
+		},
+		{
+			name:        "unclosed expression",
+			input:       "{{ project_name",
+			expectedErr: `invalid expression "{{ project_name": missing closing }}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expr.Render(tt.input, values)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func Test_EvalBool(t *testing.T) {
+	values := map[string]any{
+		"use_docker": true,
+		"frontend":   "react",
+		"linters":    []string{"eslint"},
+		"port":       8080,
+		"empty":      "",
+		"tag":        nil,
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    bool
+		expectedErr string
+	}{
+		{name: "bool var", input: "use_docker", expected: true},
+		{name: "empty string", input: "empty", expected: false},
+		{name: "not", input: "!use_docker", expected: false},
+		{name: "not keyword", input: "not empty", expected: true},
+		{name: "equal", input: `frontend == "react"`, expected: true},
+		{name: "not equal", input: `frontend != 'react'`, expected: false},
+		{name: "in list literal", input: `frontend in ["react", "vue"]`, expected: true},
+		{name: "not in list var", input: `"prettier" not in linters`, expected: true},
+		{name: "numbers", input: "port >= 1024 && port < 65536", expected: true},
+		{name: "and or", input: `use_docker and frontend == "vue" or port == 8080`, expected: true},
+		{name: "parenthesis", input: `use_docker && (frontend == "vue" || empty)`, expected: false},
+		{name: "with helper", input: `frontend | upper == "REACT"`, expected: true},
+		{name: "nil", input: "tag == nil && frontend != nil", expected: true},
+		{name: "short circuit - and", input: `empty && registry == "docker.io"`, expected: false},
+		{name: "short circuit - or", input: `use_docker || registry == "docker.io"`, expected: true},
+		{
+			name:        "undefined",
+			input:       `use_docker && registry == "docker.io"`,
+			expectedErr: "undefined vars: registry",
+		},
+		{
+			name:        "ordering a string",
+			input:       `frontend > 2`,
+			expectedErr: `invalid expression "frontend > 2": > only compares numbers, got react and 2`,
+		},
+		{
+			name:        "syntax error",
+			input:       `frontend ==`,
+			expectedErr: `invalid expression "frontend ==": unexpected end of expression`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expr.EvalBool(tt.input, values)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func Test_Rename(t *testing.T) {
+	rename := func(name string) string {
+		if name == "port" || name == "db.name" {
+			return "api." + name
+		}
+		return name
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		template    bool
+		expected    string
+		expectedErr string
+	}{
+		{name: "var", input: "port >= 1024", expected: "api.port >= 1024"},
+		{name: "nested namespace", input: `db.name != "" and not use_db`, expected: `api.db.name != "" and not use_db`},
+		{name: "functions and strings are kept", input: `upper("port") == port | lower`, expected: `upper("port") == api.port | lower`},
+		{name: "template", input: "{{ db.name }}:{{port|replace(\"0\", port)}}", template: true, expected: "{{ api.db.name }}:{{api.port|replace(\"0\", api.port)}}"},
+		{name: "syntax error", input: `port == "8080`, expectedErr: `invalid expression "port == \"8080": unterminated string`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renameFunc := expr.Rename
+			if tt.template {
+				renameFunc = expr.RenameTemplate
+			}
+			got, err := renameFunc(tt.input, rename)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func Test_Bind(t *testing.T) {
+	values := map[string]any{
+		"params": map[string]any{"message": `say "hi"`, "push": false, "remote": "origin", "tag": nil, "max_size": 1e6, "ratio": 0.25},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expr     bool
+		expected string
+	}{
+		{name: "rendered", input: "git commit -m {{ params.message }}", expected: `git commit -m say "hi"`},
+		{name: "with helpers", input: "{{ params.remote | upper }}", expected: "ORIGIN"},
+		{name: "partly bound", input: "{{ params.remote }}/{{ project_name | kebab }}", expected: "origin/{{ project_name | kebab }}"},
+		{name: "mixed expression", input: `{{ replace(params.remote, "o", project_name) }}`, expected: `{{ replace("origin", "o", project_name) }}`},
+		{name: "no var", input: `{{ now("2006") }}`, expected: `{{ now("2006") }}`},
+		{name: "condition", input: "params.push", expr: true, expected: "false"},
+		{name: "partly bound condition", input: `use_git && params.remote == "origin"`, expr: true, expected: `use_git && "origin" == "origin"`},
+		{name: "empty condition", input: "", expr: true, expected: ""},
+		{name: "nil", input: "use_docker && params.tag == nil", expr: true, expected: "use_docker && nil == nil"},
+		{name: "floats", input: "size < params.max_size && ratio > params.ratio", expr: true, expected: "size < 1000000 && ratio > 0.25"},
+		{name: "nil argument", input: `{{ replace(project_name, "-", params.tag) }}`, expected: `{{ replace(project_name, "-", nil) }}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bind := expr.Bind
+			if tt.expr {
+				bind = expr.BindExpr
+			}
+			got, err := bind(tt.input, values)
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
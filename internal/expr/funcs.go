@@ -0,0 +1,116 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type function func(args ...any) (any, error)
+
+// funcs contains every helper available in expressions.
+var funcs = map[string]function{
+	"lower": stringFunc(strings.ToLower),
+	"upper": stringFunc(strings.ToUpper),
+	"trim":  stringFunc(strings.TrimSpace),
+	"snake": stringFunc(func(s string) string { return strings.Join(lowerWords(s), "_") }),
+	"kebab": stringFunc(func(s string) string { return strings.Join(lowerWords(s), "-") }),
+	"camel": stringFunc(func(s string) string {
+		words := lowerWords(s)
+		for i := 1; i < len(words); i++ {
+			words[i] = capitalize(words[i])
+		}
+		return strings.Join(words, "")
+	}),
+	"pascal": stringFunc(func(s string) string {
+		words := lowerWords(s)
+		for i := range words {
+			words[i] = capitalize(words[i])
+		}
+		return strings.Join(words, "")
+	}),
+	"replace": func(args ...any) (any, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("replace expects 3 arguments, got %d", len(args))
+		}
+		return strings.ReplaceAll(toString(args[0]), toString(args[1]), toString(args[2])), nil
+	},
+	"join": func(args ...any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("join expects 2 arguments, got %d", len(args))
+		}
+		sep := toString(args[1])
+		switch list := args[0].(type) {
+		case []string:
+			return strings.Join(list, sep), nil
+		case []any:
+			parts := make([]string, len(list))
+			for i, elem := range list {
+				parts[i] = toString(elem)
+			}
+			return strings.Join(parts, sep), nil
+		}
+		return toString(args[0]), nil
+	},
+	"now": func(args ...any) (any, error) {
+		switch len(args) {
+		case 0:
+			return time.Now().Format(time.DateOnly), nil
+		case 1:
+			return time.Now().Format(toString(args[0])), nil
+		}
+		return nil, fmt.Errorf("now expects at most 1 argument, got %d", len(args))
+	},
+}
+
+// stringFunc converts a func(string) string into a function of the expression language.
+func stringFunc(fn func(string) string) function {
+	return func(args ...any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		return fn(toString(args[0])), nil
+	}
+}
+
+// lowerWords splits s into lowercase words.
+// Words are separated by any non alphanumeric character, or by a case change (camelCase).
+func lowerWords(s string) []string {
+	var (
+		words   []string
+		current []rune
+		runes   = []rune(s)
+	)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+func capitalize(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,401 @@
+package expr
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	identToken tokenKind = iota
+	stringToken
+	numberToken
+	pipeToken
+	lparenToken
+	rparenToken
+	commaToken
+	lbracketToken
+	rbracketToken
+	opToken
+)
+
+// operators are the two characters operators, `!`, `<` and `>` are handled on their own.
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+type token struct {
+	kind  tokenKind
+	value string
+	// start and end are the rune offsets of the token in the source
+	start, end int
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenize splits src into tokens.
+func tokenize(src string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(src)
+	)
+	for i := 0; i < len(runes); {
+		r, start, count := runes[i], i, len(tokens)
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: lparenToken, value: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: rparenToken, value: ")"})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: commaToken, value: ","})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: lbracketToken, value: "["})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: rbracketToken, value: "]"})
+			i++
+		case strings.ContainsRune("=!<>&|", r) && i+1 < len(runes) && slices.Contains(operators, string(runes[i:i+2])):
+			tokens = append(tokens, token{kind: opToken, value: string(runes[i : i+2])})
+			i += 2
+		case r == '!' || r == '<' || r == '>':
+			tokens = append(tokens, token{kind: opToken, value: string(r)})
+			i++
+		case r == '|':
+			tokens = append(tokens, token{kind: pipeToken, value: "|"})
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{kind: stringToken, value: sb.String()})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: numberToken, value: string(runes[i:j])})
+			i = j
+		case isIdentStart(r):
+			j := i + 1
+			for j < len(runes) && isIdentPart(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: identToken, value: string(runes[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+		if len(tokens) > count {
+			tokens[count].start, tokens[count].end = start, i
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens    []token
+	pos       int
+	values    map[string]any
+	undefined []string
+}
+
+func (p *exprParser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *exprParser) next() *token {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) expect(kind tokenKind, value string) error {
+	t := p.next()
+	if t == nil {
+		return fmt.Errorf("expected %q, got end of expression", value)
+	}
+	if t.kind != kind {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+// parseExpression parses a whole expression, it must consume every token.
+func (p *exprParser) parseExpression() (any, error) {
+	res, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil {
+		return nil, fmt.Errorf("unexpected %q", t.value)
+	}
+	return res, nil
+}
+
+// isOperator checks that t is the operator op, or its keyword form (and, or, not).
+func isOperator(t *token, op, keyword string) bool {
+	if t == nil {
+		return false
+	}
+	return (t.kind == opToken && t.value == op) || (t.kind == identToken && t.value == keyword)
+}
+
+// parseOr parses `and || and || ...`. The right side is not required to be defined when the left side is true.
+func (p *exprParser) parseOr() (any, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isOperator(p.peek(), "||", "or") {
+		p.next()
+		mark := len(p.undefined)
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if Truthy(left) {
+			p.undefined = p.undefined[:mark]
+			left = true
+			continue
+		}
+		left = Truthy(right)
+	}
+	return left, nil
+}
+
+// parseAnd parses `not && not && ...`. The right side is not required to be defined when the left side is false.
+func (p *exprParser) parseAnd() (any, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for isOperator(p.peek(), "&&", "and") {
+		p.next()
+		mark := len(p.undefined)
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if !Truthy(left) {
+			p.undefined = p.undefined[:mark]
+			left = false
+			continue
+		}
+		left = Truthy(right)
+	}
+	return left, nil
+}
+
+// parseNot parses `!comparison` or `not comparison`.
+func (p *exprParser) parseNot() (any, error) {
+	if isOperator(p.peek(), "!", "not") {
+		p.next()
+		res, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return !Truthy(res), nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses `pipe op pipe` where op is one of ==, !=, <, <=, >, >=, in or not in.
+func (p *exprParser) parseComparison() (any, error) {
+	left, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t == nil {
+		return left, nil
+	}
+
+	op := t.value
+	switch {
+	case t.kind == opToken && slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, op):
+		p.next()
+	case t.kind == identToken && op == "in":
+		p.next()
+	case t.kind == identToken && op == "not" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].value == "in":
+		p.next()
+		p.next()
+		op = "not in"
+	default:
+		return left, nil
+	}
+
+	right, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.undefined) > 0 {
+		return nil, nil
+	}
+	return compare(op, left, right)
+}
+
+func (p *exprParser) parsePipe() (any, error) {
+	res, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind == pipeToken; t = p.peek() {
+		p.next()
+		name := p.next()
+		if name == nil || name.kind != identToken {
+			return nil, fmt.Errorf("expected a function name after |")
+		}
+		args := []any{res}
+		if t := p.peek(); t != nil && t.kind == lparenToken {
+			more, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, more...)
+		}
+		if res, err = p.call(name.value, args); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (p *exprParser) parseTerm() (any, error) {
+	t := p.next()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	switch t.kind {
+	case stringToken:
+		return t.value, nil
+	case numberToken:
+		if i, err := strconv.Atoi(t.value); err == nil {
+			return i, nil
+		}
+		return strconv.ParseFloat(t.value, 64)
+	case lparenToken:
+		res, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return res, p.expect(rparenToken, ")")
+	case lbracketToken:
+		return p.parseList()
+	case identToken:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		}
+		if next := p.peek(); next != nil && next.kind == lparenToken {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return p.call(t.value, args)
+		}
+		v, ok := Lookup(p.values, t.value)
+		if !ok {
+			p.undefined = append(p.undefined, t.value)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.value)
+}
+
+// parseArgs parses `(arg, arg, ...)`.
+func (p *exprParser) parseArgs() ([]any, error) {
+	if err := p.expect(lparenToken, "("); err != nil {
+		return nil, err
+	}
+	var args []any
+	if t := p.peek(); t != nil && t.kind == rparenToken {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		t := p.next()
+		if t == nil {
+			return nil, fmt.Errorf("expected \")\", got end of expression")
+		}
+		if t.kind == rparenToken {
+			return args, nil
+		}
+		if t.kind != commaToken {
+			return nil, fmt.Errorf("expected \",\" or \")\", got %q", t.value)
+		}
+	}
+}
+
+// parseList parses `[elem, elem, ...]`, the opening bracket being already consumed.
+func (p *exprParser) parseList() (any, error) {
+	list := []any{}
+	if t := p.peek(); t != nil && t.kind == rbracketToken {
+		p.next()
+		return list, nil
+	}
+	for {
+		elem, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, elem)
+		t := p.next()
+		if t == nil {
+			return nil, fmt.Errorf("expected \"]\", got end of expression")
+		}
+		if t.kind == rbracketToken {
+			return list, nil
+		}
+		if t.kind != commaToken {
+			return nil, fmt.Errorf("expected \",\" or \"]\", got %q", t.value)
+		}
+	}
+}
+
+func (p *exprParser) call(name string, args []any) (any, error) {
+	fn, ok := funcs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	// an undefined var will be reported anyway, no need to run the function on it
+	if len(p.undefined) > 0 {
+		return nil, nil
+	}
+	return fn(args...)
+}
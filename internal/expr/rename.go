@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keywords are the identifiers that are not var names.
+var keywords = map[string]bool{"true": true, "false": true, "nil": true, "and": true, "or": true, "not": true, "in": true}
+
+// isVar checks that the identifier tokens[i] is a var name, not a keyword nor a function.
+func isVar(tokens []token, i int) bool {
+	return tokens[i].kind == identToken && !keywords[tokens[i].value] &&
+		(i == 0 || tokens[i-1].kind != pipeToken) &&
+		(i+1 >= len(tokens) || tokens[i+1].kind != lparenToken)
+}
+
+// replaceVars rewrites every var name of the expression src with the result of replace.
+func replaceVars(src string, replace func(name string) string) (string, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return "", SyntaxError{Expr: src, err: err}
+	}
+
+	var (
+		sb    strings.Builder
+		runes = []rune(src)
+		last  int
+	)
+	for i, t := range tokens {
+		if !isVar(tokens, i) {
+			continue
+		}
+		sb.WriteString(string(runes[last:t.start]))
+		sb.WriteString(replace(t.value))
+		last = t.end
+	}
+	sb.WriteString(string(runes[last:]))
+	return sb.String(), nil
+}
+
+// eachExpr calls fn with every expression of tmpl, and replaces the expression with its result.
+func eachExpr(tmpl string, fn func(src string) (string, error)) (string, error) {
+	var (
+		sb   strings.Builder
+		rest = tmpl
+	)
+	for {
+		start := strings.Index(rest, openDelim)
+		if start < 0 {
+			sb.WriteString(rest)
+			return sb.String(), nil
+		}
+		end := strings.Index(rest[start:], closeDelim)
+		if end < 0 {
+			return "", SyntaxError{Expr: tmpl, err: fmt.Errorf("missing closing %s", closeDelim)}
+		}
+		res, err := fn(rest[start+len(openDelim) : start+end])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(rest[:start])
+		sb.WriteString(res)
+		rest = rest[start+end+len(closeDelim):]
+	}
+}
+
+// Rename rewrites every var name referenced by the expression src, written without curly braces,
+// with the result of rename. Functions, strings and the layout of src are kept as is.
+func Rename(src string, rename func(name string) string) (string, error) {
+	return replaceVars(src, rename)
+}
+
+// RenameTemplate rewrites the var names of every expression of tmpl, see [Rename].
+func RenameTemplate(tmpl string, rename func(name string) string) (string, error) {
+	return eachExpr(tmpl, func(src string) (string, error) {
+		renamed, err := Rename(src, rename)
+		return openDelim + renamed + closeDelim, err
+	})
+}
+
+// BindExpr replaces the vars of the expression src that have a value in values with their value.
+// When every var of src has a value, src is computed and its result is returned as a literal.
+func BindExpr(src string, values map[string]any) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return src, nil
+	}
+	res, bound, err := bind(src, values)
+	if err != nil || !bound {
+		return res.(string), err
+	}
+	return literal(res), nil
+}
+
+// Bind renders the expressions of tmpl whose vars all have a value in values. In the other
+// expressions, the vars that have a value are replaced with it, see [BindExpr].
+func Bind(tmpl string, values map[string]any) (string, error) {
+	return eachExpr(tmpl, func(src string) (string, error) {
+		res, bound, err := bind(src, values)
+		if err != nil || !bound {
+			return openDelim + res.(string) + closeDelim, err
+		}
+		return toString(res), nil
+	})
+}
+
+// bind replaces the vars of src that have a value with literals. It returns the result of src and
+// true when every var has a value, src with the literals and false otherwise. An expression without
+// any var bound is left as is.
+func bind(src string, values map[string]any) (any, bool, error) {
+	var found bool
+	replaced, err := replaceVars(src, func(name string) string {
+		if v, ok := Lookup(values, name); ok {
+			found = true
+			return literal(v)
+		}
+		return name
+	})
+	if err != nil || !found {
+		return src, false, err
+	}
+	res, err := Eval(replaced, nil)
+	if _, ok := err.(UndefinedError); ok {
+		return replaced, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return res, true, nil
+}
+
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// literal writes v as a literal of the expression language.
+func literal(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return `"` + quoteReplacer.Replace(v) + `"`
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = literal(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = literal(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return toString(v)
+}
@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,8 +9,9 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
-	"cuelang.org/go/pkg/encoding/json"
-	"cuelang.org/go/pkg/encoding/yaml"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/toml"
+	"cuelang.org/go/encoding/yaml"
 )
 
 type SupportedFileType string
@@ -18,8 +20,15 @@ const (
 	JSON SupportedFileType = "json"
 	YAML SupportedFileType = "yaml"
 	YML  SupportedFileType = "yml"
+	TOML SupportedFileType = "toml"
+	CUE  SupportedFileType = "cue"
 )
 
+// WorkflowFileTypes lists the file types a workflow can be written in.
+var WorkflowFileTypes = []string{string(JSON), string(YAML), string(YML), string(TOML), string(CUE)}
+
+// CueUnmarshalFile reads a workflow file into a cue value. Positions of the value refer to the file,
+// so that errors can point to their line and column.
 func CueUnmarshalFile(ctx *cue.Context, filename string) (*cue.Value, error) {
 	var (
 		exp ast.Expr
@@ -30,15 +39,32 @@ func CueUnmarshalFile(ctx *cue.Context, filename string) (*cue.Value, error) {
 	}
 	switch strings.ReplaceAll(filepath.Ext(filename), ".", "") {
 	case string(JSON):
-		exp, err = json.Unmarshal(fileContent)
+		exp, err = json.Extract(filename, fileContent)
 		if err != nil {
 			return nil, err
 		}
 	case string(YAML), string(YML):
-		exp, err = yaml.Unmarshal(fileContent)
+		file, err := yaml.Extract(filename, fileContent)
 		if err != nil {
 			return nil, err
 		}
+		if len(file.Decls) == 0 {
+			return nil, fmt.Errorf("empty workflow file")
+		}
+		val := ctx.BuildFile(file)
+		return &val, nil
+	case string(TOML):
+		exp, err = toml.NewDecoder(filename, bytes.NewReader(fileContent)).Decode()
+		if err != nil {
+			return nil, err
+		}
+	case string(CUE):
+		// a cue workflow is evaluated as is, with its own definitions, defaults and comprehensions
+		val := ctx.CompileBytes(fileContent, cue.Filename(filename))
+		if err = val.Err(); err != nil {
+			return nil, err
+		}
+		return &val, nil
 	default:
 		return nil, fmt.Errorf("unsupported file type")
 	}
@@ -0,0 +1,219 @@
+// Package lint checks a workflow beyond its schema: installed modules and their capabilities,
+// template engines and files, and the vars used by templates.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bootengine/boot/internal/model"
+)
+
+// Severity tells if a [Problem] prevents the generation.
+type Severity string
+
+const (
+	// Error problems make the generation fail.
+	Error Severity = "error"
+	// Warning problems might be intended, but usually are mistakes.
+	Warning Severity = "warning"
+)
+
+// licenseModule is the module shipped with boot, it is never installed.
+const licenseModule = "license"
+
+// A Problem is an issue found in a workflow.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	// Path locates the offending value, e.g. steps[2].module or folder_struct/cmd/main.go.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String formats the problem on a single line.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Path, p.Message)
+}
+
+// HasErrors checks that at least one of the problems is an [Error].
+func HasErrors(problems []Problem) bool {
+	return slices.ContainsFunc(problems, func(p Problem) bool {
+		return p.Severity == Error
+	})
+}
+
+type linter struct {
+	workflow model.Workflow
+	modules  map[string]model.Module
+	declared []string
+	problems []Problem
+}
+
+// Lint checks workflow, with its includes resolved, against the installed modules. Relative template
+// paths are resolved from the current directory, the way the runner reads them.
+func Lint(workflow model.Workflow, modules []model.Module) []Problem {
+	l := &linter{
+		workflow: workflow,
+		modules:  make(map[string]model.Module, len(modules)),
+	}
+	for _, mod := range modules {
+		l.modules[mod.Name] = mod
+	}
+	l.declared = l.declaredVars()
+
+	l.lintSteps(workflow.Steps, "steps")
+	l.lintSteps(workflow.Hooks.PreGen, "hooks.pre_gen")
+	l.lintSteps(workflow.Hooks.PostGen, "hooks.post_gen")
+	l.lintSteps(workflow.Hooks.OnFailure, "hooks.on_failure")
+	l.lintFolderStruct(workflow.FolderStruct, "folder_struct")
+	return l.problems
+}
+
+func (l *linter) report(severity Severity, path, format string, args ...any) {
+	l.problems = append(l.problems, Problem{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// lintSteps checks that step names are unique in their scope, and that their modules are installed and capable
+// of their action. path locates the steps in the workflow: steps, or the steps of a hook.
+// A step group used twice repeats the names of its steps: names are only used in logs, a repeated one is a warning.
+func (l *linter) lintSteps(steps []model.Step, path string) {
+	type scopedName struct{ scope, name string }
+	names := make(map[scopedName]int, len(steps))
+	for i, step := range steps {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		key := scopedName{step.Scope, step.Name}
+		if first, ok := names[key]; ok {
+			l.report(Warning, stepPath+".name", "step name %q is already used by %s[%d]", step.Name, path, first)
+		} else {
+			names[key] = i
+		}
+
+		if step.Module == licenseModule {
+			continue
+		}
+		mod, ok := l.modules[step.Module]
+		if !ok {
+			l.report(Error, stepPath+".module", "module %q is not installed", step.Module)
+			continue
+		}
+		if !slices.Contains(model.Capabilities[mod.Type], step.Action) {
+			l.report(Error, stepPath+".action", "module %q is a %s module, it cannot %s", mod.Name, mod.Type, step.Action)
+		}
+	}
+}
+
+// lintFolderStruct checks the template engines and files, and the vars used by templates.
+func (l *linter) lintFolderStruct(fs model.FolderStruct, parent string) {
+	for _, f := range fs {
+		filerPath := path.Join(parent, f.GetName())
+		if !f.IsFile() {
+			l.lintFolderStruct(f.(model.Folder).Filers, filerPath)
+			continue
+		}
+
+		file := f.(model.File)
+		if file.TempWrapper == nil {
+			continue
+		}
+		mod, ok := l.modules[file.Engine]
+		switch {
+		case !ok:
+			l.report(Error, filerPath, "template engine %q is not installed", file.Engine)
+		case mod.Type != model.TempEngineType:
+			l.report(Error, filerPath, "module %q is a %s module, not a %s module", mod.Name, mod.Type, model.TempEngineType)
+		}
+
+		content, err := os.ReadFile(file.Filepath)
+		if err != nil {
+			l.report(Error, filerPath, "template %s cannot be read: %s", file.Filepath, err)
+			continue
+		}
+		declared := l.templateDeclared(file.Namespace)
+		for _, name := range templateVars(string(content)) {
+			if !slices.Contains(declared, name) {
+				l.report(Warning, filerPath, "template %s uses %q, which is not a declared var", file.Filepath, name)
+			}
+		}
+	}
+}
+
+// declaredVars returns the names of the vars of the workflow, included workflows' ones are merged by the parser.
+// The roots of namespaced vars (api for api.port) are declared too, and steps when steps publish outputs.
+func (l *linter) declaredVars() []string {
+	var names []string
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, v := range l.workflow.Vars {
+		add(v.Name)
+		if root, _, ok := strings.Cut(v.Name, "."); ok {
+			add(root)
+		}
+	}
+	if slices.ContainsFunc(l.workflow.Steps, func(s model.Step) bool { return s.ID != "" }) {
+		add("steps")
+	}
+	return names
+}
+
+// templateDeclared returns the vars a template of the namespace of an included workflow can use: the declared
+// ones, and the vars of the namespace without it.
+func (l *linter) templateDeclared(namespace string) []string {
+	if namespace == "" {
+		return l.declared
+	}
+	names := slices.Clone(l.declared)
+	for _, name := range l.declared {
+		if scoped, ok := strings.CutPrefix(name, namespace+"."); ok {
+			names = append(names, scoped)
+		}
+	}
+	return names
+}
+
+var (
+	templateBlockRegex = regexp.MustCompile(`(?s)\{\{(.*?)\}\}`)
+	// dottedNameRegex matches the names of go templates: {{ .name }}.
+	dottedNameRegex = regexp.MustCompile(`(?:^|[\s(|!-])\.([A-Za-z_][A-Za-z0-9_]*)`)
+	nameRegex       = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*(\()?`)
+	// templateKeywords are the names of template languages that are not vars.
+	templateKeywords = []string{
+		"if", "else", "end", "range", "with", "for", "block", "define", "template", "break", "continue",
+		"not", "and", "or", "len", "index", "print", "printf", "println", "true", "false", "nil", "none",
+	}
+)
+
+// templateVars returns the names of the vars used in a template. Go templates ({{ if .name }}) and
+// jinja-like templates ({{ name | filter }}) are supported.
+func templateVars(content string) []string {
+	var names []string
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, block := range templateBlockRegex.FindAllStringSubmatch(content, -1) {
+		dotted := dottedNameRegex.FindAllStringSubmatch(block[1], -1)
+		for _, match := range dotted {
+			add(match[1])
+		}
+		if len(dotted) > 0 {
+			continue
+		}
+		// the first name that is neither a keyword nor a function call, only its root for nested values
+		for _, match := range nameRegex.FindAllStringSubmatch(block[1], -1) {
+			name, _, _ := strings.Cut(strings.TrimSuffix(match[0], "("), ".")
+			if match[1] == "" && !slices.Contains(templateKeywords, name) {
+				add(name)
+				break
+			}
+		}
+	}
+	return names
+}
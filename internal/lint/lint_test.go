@@ -0,0 +1,111 @@
+package lint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootengine/boot/internal/lint"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func template(engine, filepath string) *model.TempWrapper {
+	return &model.TempWrapper{TemplateDef: model.TemplateDef{Engine: engine, Filepath: filepath}}
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	goTmpl := filepath.Join(dir, "main.go.tmpl")
+	jinjaTmpl := filepath.Join(dir, "README.md.tmpl")
+	missing := filepath.Join(dir, "missing.tmpl")
+	td.Require(t).CmpNoError(os.WriteFile(goTmpl, []byte("package {{ .package_name }}\n{{ if .with_cli }}// cli{{ end }}"), 0644))
+	td.Require(t).CmpNoError(os.WriteFile(jinjaTmpl, []byte("# {{ project_name | upper }}\n{{ api_port }}"), 0644))
+
+	modules := []model.Module{
+		{Name: "git", Type: model.VCSType},
+		{Name: "gotmpl", Type: model.TempEngineType},
+		{Name: "jinja", Type: model.TempEngineType},
+	}
+
+	workflow := model.Workflow{
+		Vars: model.Vars{
+			{Name: "api_port", Type: model.Number},
+			{Name: "project_name", Type: model.String},
+			{Name: "package_name", Type: model.String},
+		},
+		Steps: []model.Step{
+			{Name: "init", Module: "git", Action: model.InitAction},
+			{Name: "license", Module: "license"},
+			{Name: "init", Module: "git", Action: model.InstallLocalDepsAction},
+			{Name: "deps", Module: "npm", Action: model.InstallLocalDepsAction},
+			{Name: "commit", Module: "gti", Action: model.CommitAction},
+		},
+		Hooks: model.Hooks{
+			PostGen: []model.Step{
+				{Name: "init", Module: "git", Action: model.CommitAction},
+				{Name: "notify", Module: "slack", Action: model.InitAction},
+			},
+		},
+		FolderStruct: model.FolderStruct{
+			model.File{Name: "main.go", TempWrapper: template("gotmpl", goTmpl)},
+			model.Folder{Name: "docs", Filers: model.FolderStruct{
+				model.File{Name: "README.md", TempWrapper: template("jinja", jinjaTmpl)},
+				model.File{Name: "LICENSE.md", TempWrapper: template("git", jinjaTmpl)},
+				model.File{Name: "CHANGELOG.md", TempWrapper: template("mustache", missing)},
+			}},
+		},
+	}
+
+	problems := lint.Lint(workflow, modules)
+	td.Cmp(t, problems, []lint.Problem{
+		{Severity: lint.Warning, Path: "steps[2].name", Message: `step name "init" is already used by steps[0]`},
+		{Severity: lint.Error, Path: "steps[2].action", Message: `module "git" is a vcs module, it cannot installLocalDeps`},
+		{Severity: lint.Error, Path: "steps[3].module", Message: `module "npm" is not installed`},
+		{Severity: lint.Error, Path: "steps[4].module", Message: `module "gti" is not installed`},
+		{Severity: lint.Error, Path: "hooks.post_gen[1].module", Message: `module "slack" is not installed`},
+		{Severity: lint.Warning, Path: "folder_struct/main.go", Message: `template ` + goTmpl + ` uses "with_cli", which is not a declared var`},
+		{Severity: lint.Error, Path: "folder_struct/docs/LICENSE.md", Message: `module "git" is a vcs module, not a template_engine module`},
+		{Severity: lint.Error, Path: "folder_struct/docs/CHANGELOG.md", Message: `template engine "mustache" is not installed`},
+		{Severity: lint.Error, Path: "folder_struct/docs/CHANGELOG.md", Message: "template " + missing + " cannot be read: open " + missing + ": no such file or directory"},
+	})
+	td.Cmp(t, lint.HasErrors(problems), true)
+
+	td.Cmp(t, lint.Lint(model.Workflow{Steps: []model.Step{{Name: "license", Module: "license"}}}, nil), td.Empty())
+}
+
+func TestLint_Includes(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "main.go.tmpl")
+	td.Require(t).CmpNoError(os.WriteFile(tmpl, []byte("{{ .service_name }} {{ .api.port }} {{ .steps.init.outputs.version }}"), 0644))
+
+	modules := []model.Module{
+		{Name: "go", Type: model.CmdType},
+		{Name: "gotmpl", Type: model.TempEngineType},
+	}
+	namespaced := func(namespace string) *model.TempWrapper {
+		wrapper := template("gotmpl", tmpl)
+		wrapper.Namespace = namespace
+		return wrapper
+	}
+
+	// the api and worker includes of the same workflow
+	workflow := model.Workflow{
+		Vars: model.Vars{
+			{Name: "api.service_name", Type: model.String},
+			{Name: "api.port", Type: model.Int},
+		},
+		Steps: []model.Step{
+			{Name: "go mod init", ID: "init", Module: "go", Action: model.InitAction, Scope: "api"},
+			{Name: "go mod init", Module: "go", Action: model.InitAction, Scope: "worker"},
+		},
+		FolderStruct: model.FolderStruct{
+			model.File{Name: "main.go", TempWrapper: namespaced("api")},
+			model.File{Name: "cmd.go", TempWrapper: namespaced("")},
+		},
+	}
+
+	td.Cmp(t, lint.Lint(workflow, modules), []lint.Problem{
+		{Severity: lint.Warning, Path: "folder_struct/cmd.go", Message: `template ` + tmpl + ` uses "service_name", which is not a declared var`},
+	})
+}
@@ -0,0 +1,38 @@
+version: 2
+
+config: {
+	create_root:  true
+	unrestricted: false
+}
+
+#requiredString: {
+	type:     "string"
+	required: true
+	...
+}
+
+vars: [
+	{name: "project_name"} & #requiredString,
+	{name: "author_github_name"} & #requiredString,
+	{name: "license", type: "license", required: false},
+]
+
+_deps: ["bubbletea", "log"]
+
+steps: [
+	{name: "git init", module: "git", action: "init"},
+	{name: "go mod init", module: "go", action: "init"},
+	{
+		name:   "go get deps"
+		module: "go"
+		action: "installLocalDeps"
+		cwd:    "frontend"
+		params: [for d in _deps {"github.com/charmbracelet/\(d)"}]
+	},
+]
+
+folder_struct: [
+	{cmd: ["install", "remove", "root.go"]},
+	"internal",
+	{"main.go": template: {filepath: "./temp.go", engine: "jinja2"}},
+]
@@ -7,8 +7,12 @@ type Config struct {
 	Includes     []Include `json:"includes,omitempty,omitzero"`
 }
 
-// Include represent all the information needed to import another config file
+// Include represent all the information needed to import another config file.
+// With gives values to the vars of the included workflow, they are not prompted.
+// When Namespace is set, the vars of the included workflow are renamed `as.varname`.
 type Include struct {
-	From string `json:"from"`
-	As   string `json:"as"`
+	From      string         `json:"from"`
+	As        string         `json:"as"`
+	With      map[string]any `json:"with,omitempty" yaml:"with,omitempty"`
+	Namespace bool           `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 }
@@ -1,70 +1,122 @@
 package model
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"path"
 	"regexp"
-	"strings"
 )
 
+// exprRegex matches the expressions of a name, like {{ project_name }}, rendered once the vars are collected.
+var exprRegex = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// ext returns the extension of name, the dots of its expressions are ignored: {{ config.name }} is a folder.
+func ext(name string) string {
+	return path.Ext(exprRegex.ReplaceAllString(name, "_"))
+}
+
 // Filer defines the family of types in folder_struct definition.
 // Filer types are either File or Folder.
 type Filer interface {
 	IsFile() bool
 	GetName() string
+	GetWhen() string
 }
 
 type TemplateDef struct {
-	Engine   string // template engine to run
-	Filepath string // path of the template to apply
+	Engine   string `json:"engine" yaml:"engine"`     // template engine to run
+	Filepath string `json:"filepath" yaml:"filepath"` // path of the template to apply
 }
 
 // A File is one of the two types that can be defined in folder_struct.
-// It has a Name, and it can have a template definition.
+// It has a Name, that can use expressions, and it can have a template definition.
 // The content is retrieved at runtime from the template definition.
+// When set, the When expression decides if the file is created.
 type File struct {
 	Name         string              // name of the file
 	Content      string              `json:"-"` // content of the file
+	When         string              `json:"-"` // condition to create the file
 	*TempWrapper `json:",omitempty"` // template definition of the file
 }
 
 type TempWrapper struct {
 	TemplateDef `json:"template"`
+	// Namespace is the namespace of the included workflow the template comes from,
+	// its vars are given to the template without their namespace.
+	Namespace string `json:"-"`
+}
+
+// fileSpec is the object form of a file: {"main.go": {"template": {...}, "when": "..."}}.
+// Namespace is only set for the templates of included workflows, in the state of a generation.
+type fileSpec struct {
+	Template  *TemplateDef `json:"template,omitempty" yaml:"template,omitempty"`
+	When      string       `json:"when,omitempty" yaml:"when,omitempty"`
+	Namespace string       `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// folderSpec is the object form of a folder with a condition: {"docker": {"when": "...", "children": [...]}}.
+type folderSpec struct {
+	When     string                 `json:"when,omitempty" yaml:"when,omitempty"`
+	Children GeneratingFolderStruct `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+var (
+	errNotAFile   = errors.New("the data your unmarshaling is not a file")
+	errNotAFolder = errors.New("the data your unmarshaling is not a folder")
+)
+
+// isObject checks that data is a json object.
+func isObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// splitFiler returns the name and the raw definition of a filer written as a single key object.
+func splitFiler(data []byte) (string, json.RawMessage, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", nil, err
+	}
+	if len(m) != 1 {
+		return "", nil, fmt.Errorf("a folder_struct entry must have exactly one name, got %d", len(m))
+	}
+	for name, def := range m {
+		return name, def, nil
+	}
+	return "", nil, nil
 }
 
 // UnmarshalJSON implementes encoding/json.Unmarshaller on File type
 func (f *File) UnmarshalJSON(data []byte) error {
-	reg := regexp.MustCompile(`\{.+\}`)
-	if reg.Match(data) {
-		s := string(data)[1 : len(data)-1]
-		before, after, _ := strings.Cut(s, ":")
-
-		before = strings.ReplaceAll(before, `"`, "")
-
-		if path.Ext(before) != "" {
-			f.Name = before
-			var tmp TempWrapper
-			err := json.Unmarshal([]byte(after), &tmp)
-			if err != nil {
-				return err
-			}
-			f.TempWrapper = &tmp
-		} else {
-			return fmt.Errorf("the data your unmarshaling is not a file")
+	if isObject(data) {
+		name, def, err := splitFiler(data)
+		if err != nil {
+			return err
+		}
+		if ext(name) == "" {
+			return errNotAFile
+		}
+		var spec fileSpec
+		if err = json.Unmarshal(def, &spec); err != nil {
+			return err
+		}
+		f.Name, f.When = name, spec.When
+		if spec.Template != nil {
+			f.TempWrapper = &TempWrapper{TemplateDef: *spec.Template, Namespace: spec.Namespace}
 		}
-
 		return nil
 	}
-	s := string(data)[1 : len(data)-1]
-	if path.Ext(s) != "" {
-		f.Name = s
-	} else {
-		return fmt.Errorf("the data your unmarshaling is not a file")
-	}
 
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	if ext(name) == "" {
+		return errNotAFile
+	}
+	f.Name = name
 	return nil
 }
 
@@ -77,47 +129,56 @@ func (f File) GetName() string {
 	return f.Name
 }
 
+// GetWhen returns the condition to create the file.
+func (f File) GetWhen() string {
+	return f.When
+}
+
 // A Folder is one of the two types that can be defined in folder_struct.
-// It has a Name, and it can have children as an array of Filer.
+// It has a Name, that can use expressions, and it can have children as an array of Filer.
+// When set, the When expression decides if the folder (and its children) is created.
 type Folder struct {
 	Name   string
+	When   string `json:"-"`
 	Filers FolderStruct
 }
 
-// cleanSpaces removes all spaces and newline.
-// the result should be a []byte containing an inlined string without spaces.
-func cleanSpaces(data []byte) []byte {
-	s := string(data)
-	s = strings.ReplaceAll(s, "\n", "")
-	s = strings.ReplaceAll(s, "\t", "")
-	data = []byte(s)
-	return data
-}
-
 // UnmarshalJSON implementes encoding/json.Unmarshaller on Folder type
 func (f *Folder) UnmarshalJSON(data []byte) error {
-	data = cleanSpaces(data)
-	reg := regexp.MustCompile(`\{.+\}`)
-	if reg.Match(data) {
-		s := string(data)[1 : len(data)-1]
-		before, after, _ := strings.Cut(s, ":")
-
-		before = strings.ReplaceAll(before, `"`, "")
-		f.Name = before
-		var filers FolderStruct
-		err := json.Unmarshal([]byte(after), &filers)
+	if isObject(data) {
+		name, def, err := splitFiler(data)
 		if err != nil {
 			return err
 		}
+		f.Name = name
+		if isObject(def) {
+			var spec struct {
+				When     string       `json:"when"`
+				Children FolderStruct `json:"children"`
+			}
+			if err = json.Unmarshal(def, &spec); err != nil {
+				return err
+			}
+			f.When, f.Filers = spec.When, spec.Children
+			return nil
+		}
+		var filers FolderStruct
+		if err = json.Unmarshal(def, &filers); err != nil {
+			return err
+		}
 		f.Filers = filers
-
 		return nil
 	}
-	s := string(data)[1 : len(data)-1]
-	if path.Ext(s) == "" {
-		f.Name = s
-		f.Filers = nil
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	if ext(name) != "" {
+		return errNotAFolder
 	}
+	f.Name = name
+	f.Filers = nil
 	return nil
 }
 
@@ -130,93 +191,33 @@ func (f Folder) GetName() string {
 	return f.Name
 }
 
+// GetWhen returns the condition to create the folder.
+func (f Folder) GetWhen() string {
+	return f.When
+}
+
 // FolderStruct is a user-defined folder_struct. It's just an array of Filer.
 type FolderStruct []Filer
 
 // UnmarshalJSON implementes encoding/json.Unmarshaller on FolderStruct type
 func (f *FolderStruct) UnmarshalJSON(data []byte) error {
-	data = cleanSpaces(data)
-	var (
-		dec             = json.NewDecoder(strings.NewReader(string(data)))
-		parts           = []string{}
-		s               = ""
-		lastOpenedDelim = ""
-		countOdd        = 0
-		countOpenDelim  = 0
-	)
-	for {
-		t, err := dec.Token()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		if dec.InputOffset() == 1 {
-			continue
-		}
-
-		// the idea is to check on matching delimiter to parse object/array into `s`.
-		// once `s` is complete (previously opened delim are closed) we append it to `parts` and reinit `s` to empty string
-		stringed := fmt.Sprintf("%v", t)
-
-		if _, ok := t.(json.Delim); ok {
-			if stringed == "{" || stringed == "[" {
-				if stringed == "{" {
-					countOdd = 1
-				}
-				countOpenDelim++
-				s += stringed
-				lastOpenedDelim = stringed
-			} else {
-				countOpenDelim--
-				s += stringed
-
-				if countOpenDelim == 0 {
-					parts = append(parts, s)
-					s = ""
-				}
-			}
-			continue
-		}
-		stringed = fmt.Sprintf("%q", stringed)
-		if countOpenDelim == 0 {
-			parts = append(parts, stringed)
-		} else {
-			if lastOpenedDelim == "[" && dec.More() {
-				stringed += ","
-			}
-			if lastOpenedDelim == "{" && dec.More() {
-				if countOdd%2 == 0 {
-					stringed += ","
-				} else {
-					stringed += ":"
-				}
-				countOdd++
-			}
-			s += stringed
-		}
-
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
 	}
 
-	var errs []error
-	var file File
-	var folder Folder
-
-	for _, s := range parts {
-		err := json.Unmarshal([]byte(s), &file)
-		if err != nil {
-			errs = append(errs, err)
-			err = json.Unmarshal([]byte(s), &folder)
-			if err != nil {
-				errs = append(errs, err)
-				return errors.Join(errs...)
-			} else {
-				*f = append(*f, folder)
-			}
-		} else {
+	for _, part := range parts {
+		var file File
+		fileErr := json.Unmarshal(part, &file)
+		if fileErr == nil {
 			*f = append(*f, file)
+			continue
 		}
+		var folder Folder
+		if err := json.Unmarshal(part, &folder); err != nil {
+			return errors.Join(fileErr, err)
+		}
+		*f = append(*f, folder)
 	}
 	return nil
 }
@@ -291,9 +292,24 @@ func (f FolderStruct) Convert() GeneratingFolderStruct {
 	for i, filer := range f {
 		if filer.IsFile() {
 			file := filer.(File)
-			res[i] = file.Name
+			if file.TempWrapper == nil && file.When == "" {
+				res[i] = file.Name
+				continue
+			}
+			spec := fileSpec{When: file.When}
+			if file.TempWrapper != nil {
+				spec.Template, spec.Namespace = &file.TemplateDef, file.Namespace
+			}
+			res[i] = map[string]fileSpec{file.Name: spec}
 		} else {
 			folder := filer.(Folder)
+			if folder.When != "" {
+				res[i] = map[string]folderSpec{folder.Name: {
+					When:     folder.When,
+					Children: folder.Filers.Convert(),
+				}}
+				continue
+			}
 			convertedFolder := make(map[string][]any)
 			convertedFolder[folder.Name] = folder.Filers.Convert()
 			res[i] = convertedFolder
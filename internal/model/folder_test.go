@@ -49,6 +49,17 @@ func Test_FolderUnmarshal(t *testing.T) {
 				},
 			},
 		},
+		{
+			testname: "valid - names with expressions",
+			input:    `{"{{ project_name }}":["{{ config.name }}", "{{ project_name | snake }}.go"]}`,
+			expected: model.Folder{
+				Name: "{{ project_name }}",
+				Filers: model.FolderStruct{
+					model.Folder{Name: "{{ config.name }}"},
+					model.File{Name: "{{ project_name | snake }}.go"},
+				},
+			},
+		},
 		{
 			testname: "valid - only folders - including complex",
 			input:    `{"root":["internal", "pkg", {"cmd": ["install", "remove"]}]}`,
@@ -99,6 +110,25 @@ func Test_FolderUnmarshal(t *testing.T) {
 				},
 			},
 		},
+		{
+			testname: "valid - conditional files and folders",
+			input:    `{"root":[{"docker": {"when": "use_docker", "children": ["Dockerfile", {"compose.yaml": {"when": "registry"}}]}}, {"main.go": {"when": "true", "template": {"filepath": "./main.go.j2", "engine": "jinja"}}}]}`,
+			expected: model.Folder{
+				Name: "root",
+				Filers: model.FolderStruct{
+					model.Folder{Name: "docker", When: "use_docker", Filers: model.FolderStruct{
+						model.Folder{Name: "Dockerfile"},
+						model.File{Name: "compose.yaml", When: "registry"},
+					}},
+					model.File{Name: "main.go", When: "true", TempWrapper: &model.TempWrapper{
+						TemplateDef: model.TemplateDef{
+							Filepath: "./main.go.j2",
+							Engine:   "jinja",
+						},
+					}},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
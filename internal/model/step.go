@@ -1,12 +1,133 @@
 package model
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 // A Step define an action that will be executed in the current [Workflow].
 // It has a Name used for logging purpose, it will calls an Action from a installed Module.
 // This Action will be run in the CurrentWorkingDir (project_root or "." are default value).
+// The Name, CurrentWorkingDir and Params can use expressions, like {{ project_name }}, rendered with the collected values.
+// When set, the When expression decides if the step is executed.
+// A step with an ID can be needed by other steps: a step with Needs runs once the steps it needs are done,
+// a step without Needs runs once every step declared before it is done, while a step with empty Needs
+// (needs: []) runs right away.
+// The outputs of a step with an ID, published by its plugin or the stdout of its command, are used by
+// the next steps as {{ steps.<id>.outputs.<name> }}.
+// A step with Use is replaced by the steps of the [StepGroup] it names, With gives the params of the group.
+// The ids of the steps of the group are prefixed with the ID of the step using it, or <group>_<index> without ID.
+// The steps of an included workflow are scoped to its $alias folder: their root, CurrentWorkingDir
+// included, is the Scope folder of the project.
+// A failed step is run again up to Retries times, each attempt being stopped after Timeout (a Go duration).
+// When ContinueOnError is set, the generation goes on once the step failed.
 type Step struct {
-	Name              string
-	Module            string
-	Action            ModuleAction
-	CurrentWorkingDir string   `json:"cwd,omitempty" yaml:"cwd,omitempty"`
-	Params            []string `json:"params,omitempty" yaml:"params,omitempty"`
+	Name              string         `json:"name"`
+	Module            string         `json:"module"`
+	Action            ModuleAction   `json:"action,omitempty" yaml:"action,omitempty"`
+	CurrentWorkingDir string         `json:"cwd,omitempty" yaml:"cwd,omitempty"`
+	Params            []string       `json:"params,omitempty" yaml:"params,omitempty"`
+	When              string         `json:"when,omitempty" yaml:"when,omitempty"`
+	ID                string         `json:"id,omitempty" yaml:"id,omitempty"`
+	Needs             *[]string      `json:"needs,omitempty" yaml:"needs,omitempty"`
+	Scope             string         `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Use               string         `json:"use,omitempty" yaml:"use,omitempty"`
+	With              map[string]any `json:"with,omitempty" yaml:"with,omitempty"`
+	Retries           int            `json:"retries,omitempty" yaml:"retries,omitempty"`
+	Timeout           string         `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ContinueOnError   bool           `json:"continue_on_error,omitempty" yaml:"continue_on_error,omitempty"`
+}
+
+// A StepGroup is a sequence of steps defined once in a [Workflow], and used by name in its steps.
+// Its steps refer to the Params as {{ params.name }}.
+type StepGroup struct {
+	Params []StepGroupParam `json:"params,omitempty" yaml:"params,omitempty"`
+	Steps  []Step           `json:"steps"`
+}
+
+// A StepGroupParam is a param of a [StepGroup]. A param without Default must be given by the steps using the group.
+type StepGroupParam struct {
+	Name    string `json:"name"`
+	Default any    `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// StepDependencies returns, for every step, the index of the steps it waits for, see [Step].
+// It fails when an id is used twice, when a step needs an unknown step, or when steps need each other.
+func StepDependencies(steps []Step) ([][]int, error) {
+	ids := make(map[string]int)
+	for i, s := range steps {
+		if s.ID == "" {
+			continue
+		}
+		if j, ok := ids[s.ID]; ok {
+			return nil, fmt.Errorf("steps %q and %q have the same id %s", steps[j].Name, s.Name, s.ID)
+		}
+		ids[s.ID] = i
+	}
+
+	deps := make([][]int, len(steps))
+	for i, s := range steps {
+		if s.Needs == nil {
+			for j := range i {
+				deps[i] = append(deps[i], j)
+			}
+			continue
+		}
+		for _, id := range *s.Needs {
+			j, ok := ids[id]
+			if !ok {
+				return nil, fmt.Errorf("step %q needs the unknown step %s", s.Name, id)
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	if cycle := findCycle(deps); cycle != nil {
+		names := make([]string, len(cycle))
+		for i, j := range cycle {
+			names[i] = steps[j].ID
+		}
+		return nil, fmt.Errorf("steps need each other: %s", strings.Join(names, " -> "))
+	}
+	return deps, nil
+}
+
+// findCycle returns the indexes of a cycle of deps, the first index repeated at the end, nil without cycle.
+func findCycle(deps [][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+	var path []int
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range deps[i] {
+			switch state[j] {
+			case visiting:
+				return append(slices.Clone(path[slices.Index(path, j):]), j)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range deps {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
 }
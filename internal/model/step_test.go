@@ -0,0 +1,74 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_StepDependencies(t *testing.T) {
+	tests := []struct {
+		testname    string
+		steps       []model.Step
+		expected    [][]int
+		expectedErr string
+	}{
+		{
+			testname: "sequential",
+			steps:    []model.Step{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			expected: [][]int{nil, {0}, {0, 1}},
+		},
+		{
+			testname: "needs",
+			steps: []model.Step{
+				{Name: "init", ID: "init"},
+				{Name: "frontend", ID: "frontend", Needs: &[]string{"init"}},
+				{Name: "backend", ID: "backend", Needs: &[]string{"init"}},
+				{Name: "commit"},
+				{Name: "lint", Needs: &[]string{"frontend"}},
+			},
+			expected: [][]int{nil, {0}, {0}, {0, 1, 2}, {1}},
+		},
+		{
+			testname: "no needs",
+			steps: []model.Step{
+				{Name: "frontend", Needs: &[]string{}},
+				{Name: "backend", Needs: &[]string{}},
+				{Name: "commit"},
+			},
+			expected: [][]int{nil, nil, {0, 1}},
+		},
+		{
+			testname:    "same id",
+			steps:       []model.Step{{Name: "a", ID: "init"}, {Name: "b", ID: "init"}},
+			expectedErr: `steps "a" and "b" have the same id init`,
+		},
+		{
+			testname:    "unknown step",
+			steps:       []model.Step{{Name: "a", Needs: &[]string{"init"}}},
+			expectedErr: `step "a" needs the unknown step init`,
+		},
+		{
+			testname: "cycle",
+			steps: []model.Step{
+				{Name: "a", ID: "a", Needs: &[]string{"c"}},
+				{Name: "b", ID: "b", Needs: &[]string{"a"}},
+				{Name: "c", ID: "c", Needs: &[]string{"b"}},
+			},
+			expectedErr: "steps need each other: a -> c -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testname, func(t *testing.T) {
+			got, err := model.StepDependencies(tt.steps)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
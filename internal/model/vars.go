@@ -1,5 +1,12 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
 // Vars is an array of [Var]
 type Vars []Var
 
@@ -7,10 +14,125 @@ type Vars []Var
 type ValueType string
 
 // Var is a user-defined variable in a [Workflow]. It has a Name, a Type ([ValueType]) and a flag if Required.
+// [Select] and [MultiSelect] vars also have Options the user chooses from.
+// The remaining fields help the user while prompting and define the rules checked by [Var.Validate].
+// A [Computed] var is never prompted, its value is the result of its Expr.
+// When set, the When expression decides, from the values collected so far, if the var is used at all.
+// A Value, given by the including workflow, fixes the value of the var: it is never prompted.
 type Var struct {
-	Name     string    `json:"name"`
-	Type     ValueType `json:"type"`
-	Required bool      `json:"required"`
+	Name         string      `json:"name"`
+	Type         ValueType   `json:"type"`
+	Required     bool        `json:"required"`
+	Options      []VarOption `json:"options,omitempty" yaml:"options,omitempty"`
+	Default      any         `json:"default,omitempty" yaml:"default,omitempty"`
+	Description  string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Placeholder  string      `json:"placeholder,omitempty" yaml:"placeholder,omitempty"`
+	Pattern      string      `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinLength    int         `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength    int         `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	ErrorMessage string      `json:"error_message,omitempty" yaml:"error_message,omitempty"`
+	Expr         string      `json:"expr,omitempty" yaml:"expr,omitempty"`
+	When         string      `json:"when,omitempty" yaml:"when,omitempty"`
+	Min          *float64    `json:"min,omitempty" yaml:"min,omitempty"`
+	Max          *float64    `json:"max,omitempty" yaml:"max,omitempty"`
+	Value        any         `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// ErrRequiredVar is returned by [Var.Validate] when a Required var has an empty value.
+var ErrRequiredVar = errors.New("a value is required")
+
+// Validate checks value against the rules of the var: Required, Pattern, MinLength and MaxLength
+// for strings and each element of lists, Min and Max for numbers.
+// The ErrorMessage, when set, replaces the error of a broken Pattern, length or range rule.
+func (v Var) Validate(value any) error {
+	switch value := value.(type) {
+	case string:
+		if value == "" {
+			if v.Required {
+				return ErrRequiredVar
+			}
+			return nil
+		}
+		return v.validateString(value)
+	case []string:
+		if len(value) == 0 && v.Required {
+			return ErrRequiredVar
+		}
+		if v.Type == List {
+			for _, elem := range value {
+				if err := v.validateString(elem); err != nil {
+					return fmt.Errorf("%s: %w", elem, err)
+				}
+			}
+		}
+	case int:
+		return v.validateNumber(float64(value))
+	case float64:
+		return v.validateNumber(value)
+	}
+	return nil
+}
+
+func (v Var) validateNumber(value float64) error {
+	var err error
+	switch {
+	case v.Min != nil && value < *v.Min:
+		err = fmt.Errorf("must be greater than or equal to %v", *v.Min)
+	case v.Max != nil && value > *v.Max:
+		err = fmt.Errorf("must be less than or equal to %v", *v.Max)
+	}
+	if err != nil && v.ErrorMessage != "" {
+		return errors.New(v.ErrorMessage)
+	}
+	return err
+}
+
+func (v Var) validateString(value string) error {
+	var err error
+	length := utf8.RuneCountInString(value)
+	switch {
+	case v.MinLength > 0 && length < v.MinLength:
+		err = fmt.Errorf("must be at least %d characters long", v.MinLength)
+	case v.MaxLength > 0 && length > v.MaxLength:
+		err = fmt.Errorf("must be at most %d characters long", v.MaxLength)
+	case v.Pattern != "":
+		reg, regErr := regexp.Compile(v.Pattern)
+		if regErr != nil {
+			return fmt.Errorf("invalid pattern %q: %w", v.Pattern, regErr)
+		}
+		if !reg.MatchString(value) {
+			err = fmt.Errorf("must match the pattern %s", v.Pattern)
+		}
+	}
+	if err != nil && v.ErrorMessage != "" {
+		return errors.New(v.ErrorMessage)
+	}
+	return err
+}
+
+// VarOption is one of the choices of a [Select] or [MultiSelect] [Var].
+// The Label is displayed to the user, the Value is what ends up in templates and plugins.
+type VarOption struct {
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+	Value string `json:"value"`
+}
+
+// GetLabel returns the Label of the option, or its Value if no Label is set.
+func (o VarOption) GetLabel() string {
+	if o.Label == "" {
+		return o.Value
+	}
+	return o.Label
+}
+
+// HasOption checks that value is one of the Options of the var.
+func (v Var) HasOption(value string) bool {
+	for _, o := range v.Options {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
 }
 
 const (
@@ -19,4 +141,9 @@ const (
 	Password    ValueType = "password"
 	Select      ValueType = "select"
 	MultiSelect ValueType = "multi"
+	Computed    ValueType = "computed"
+	Bool        ValueType = "bool"
+	Int         ValueType = "int"
+	Number      ValueType = "number"
+	List        ValueType = "list"
 )
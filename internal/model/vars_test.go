@@ -0,0 +1,116 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_VarValidate(t *testing.T) {
+	npmName := model.Var{
+		Name:      "project_name",
+		Type:      model.String,
+		Required:  true,
+		Pattern:   "^[a-z0-9-]+$",
+		MinLength: 2,
+		MaxLength: 10,
+	}
+
+	tests := []struct {
+		testname    string
+		v           model.Var
+		value       any
+		expectedErr string
+	}{
+		{
+			testname: "valid",
+			v:        npmName,
+			value:    "my-app",
+		},
+		{
+			testname:    "invalid - required",
+			v:           npmName,
+			value:       "",
+			expectedErr: "a value is required",
+		},
+		{
+			testname: "valid - empty but not required",
+			v:        model.Var{Name: "description", Type: model.String, MinLength: 5},
+			value:    "",
+		},
+		{
+			testname:    "invalid - pattern",
+			v:           npmName,
+			value:       "My App",
+			expectedErr: "must match the pattern ^[a-z0-9-]+$",
+		},
+		{
+			testname:    "invalid - too short",
+			v:           npmName,
+			value:       "a",
+			expectedErr: "must be at least 2 characters long",
+		},
+		{
+			testname:    "invalid - too long",
+			v:           npmName,
+			value:       "my-super-long-app",
+			expectedErr: "must be at most 10 characters long",
+		},
+		{
+			testname: "invalid - custom error message",
+			v: model.Var{
+				Name:         "project_name",
+				Type:         model.String,
+				Pattern:      "^[a-z]+$",
+				ErrorMessage: "npm package names are lowercase",
+			},
+			value:       "MyApp",
+			expectedErr: "npm package names are lowercase",
+		},
+		{
+			testname:    "invalid - required multi",
+			v:           model.Var{Name: "linters", Type: model.MultiSelect, Required: true},
+			value:       []string{},
+			expectedErr: "a value is required",
+		},
+		{
+			testname: "valid - number in range",
+			v:        model.Var{Name: "port", Type: model.Int, Min: ptr(1.0), Max: ptr(65535.0)},
+			value:    8080,
+		},
+		{
+			testname:    "invalid - number too small",
+			v:           model.Var{Name: "port", Type: model.Int, Min: ptr(1.0), Max: ptr(65535.0)},
+			value:       0,
+			expectedErr: "must be greater than or equal to 1",
+		},
+		{
+			testname:    "invalid - number too big",
+			v:           model.Var{Name: "ratio", Type: model.Number, Max: ptr(1.0)},
+			value:       1.5,
+			expectedErr: "must be less than or equal to 1",
+		},
+		{
+			testname:    "invalid - list element pattern",
+			v:           model.Var{Name: "deps", Type: model.List, Pattern: "^[a-z]+$"},
+			value:       []string{"log", "Cobra"},
+			expectedErr: "Cobra: must match the pattern ^[a-z]+$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testname, func(t *testing.T) {
+			err := tt.v.Validate(tt.value)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
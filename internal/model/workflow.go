@@ -3,15 +3,43 @@ package model
 
 // Workflow is the result of what has been parsed from user's input.
 type Workflow struct {
-	Config       Config       `json:"config"`
-	Vars         Vars         `json:"vars"`
-	Steps        []Step       `json:"steps"`
-	FolderStruct FolderStruct `json:"folder_struct"`
+	Version      int                  `json:"version"`
+	Config       Config               `json:"config"`
+	Vars         Vars                 `json:"vars"`
+	StepGroups   map[string]StepGroup `json:"step_groups,omitempty" yaml:"step_groups,omitempty"`
+	Steps        []Step               `json:"steps"`
+	Hooks        Hooks                `json:"hooks,omitzero" yaml:"hooks,omitempty"`
+	FolderStruct FolderStruct         `json:"folder_struct"`
+}
+
+// Hooks are steps run around the generation: PreGen ones once the vars are collected, before anything
+// is created, PostGen ones once every step is done, and OnFailure ones when the generation fails.
+// OnFailure steps find what failed in their values, see the runner.
+type Hooks struct {
+	PreGen    []Step `json:"pre_gen,omitempty" yaml:"pre_gen,omitempty"`
+	PostGen   []Step `json:"post_gen,omitempty" yaml:"post_gen,omitempty"`
+	OnFailure []Step `json:"on_failure,omitempty" yaml:"on_failure,omitempty"`
 }
 
 type GeneratingWorkflow struct {
+	Version      int                    `json:"version"`
 	Config       Config                 `json:"config"`
 	Vars         Vars                   `json:"vars"`
+	StepGroups   map[string]StepGroup   `json:"step_groups,omitempty" yaml:"step_groups,omitempty"`
 	Steps        []Step                 `json:"steps"`
+	Hooks        Hooks                  `json:"hooks,omitzero" yaml:"hooks,omitempty"`
 	FolderStruct GeneratingFolderStruct `json:"folder_struct" yaml:"folder_struct"`
 }
+
+// Convert returns the [GeneratingWorkflow] of the workflow, the form written in workflow files.
+func (w Workflow) Convert() GeneratingWorkflow {
+	return GeneratingWorkflow{
+		Version:      w.Version,
+		Config:       w.Config,
+		Vars:         w.Vars,
+		StepGroups:   w.StepGroups,
+		Steps:        w.Steps,
+		Hooks:        w.Hooks,
+		FolderStruct: w.FolderStruct.Convert(),
+	}
+}
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	cacheEntryFile  = "entry.json"
+	cacheContentDir = "content"
+)
+
+// A Cache keeps the remote workflows on disk, keyed by url and revision,
+// so that they are fetched once and can be reused offline.
+type Cache struct {
+	// Dir is the directory holding the cached workflows.
+	Dir string
+	// Refresh fetches the workflows again instead of reusing the cached copies.
+	Refresh bool
+}
+
+// A CacheEntry describes a cached remote workflow.
+type CacheEntry struct {
+	// Source is the url of the file or of the git repository.
+	Source string `json:"source"`
+	// Ref is the requested branch, tag or commit of a git repository.
+	Ref string `json:"ref,omitempty"`
+	// Revision is the commit checked out for a git repository.
+	Revision  string    `json:"revision,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// Dir is where the entry is stored.
+	Dir string `json:"-"`
+}
+
+// DefaultCacheDir returns the cache directory inside the bootengine config dir.
+func DefaultCacheDir() (string, error) {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(config, "bootengine", "cache"), nil
+}
+
+// NewCache returns a [Cache] stored in [DefaultCacheDir].
+func NewCache(refresh bool) (*Cache, error) {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir, Refresh: refresh}, nil
+}
+
+// entryDir returns the directory of the cached copy of s. Every workflow
+// of a git repository shares the same checkout.
+func (c Cache) entryDir(s RemoteSource) string {
+	sum := sha256.Sum256([]byte(s.URL + "@" + s.Ref))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:8]))
+}
+
+// fetch returns the path of the local copy of the workflow, it is only fetched when not cached yet
+// or when refreshing. A failed fetch leaves the previous copy untouched.
+func (c Cache) fetch(ctx context.Context, s RemoteSource) (string, error) {
+	dir := c.entryDir(s)
+	if !c.Refresh {
+		if _, err := os.Stat(filepath.Join(dir, cacheEntryFile)); err == nil {
+			return s.localPath(filepath.Join(dir, cacheContentDir))
+		}
+	}
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(c.Dir, ".fetch-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	content := filepath.Join(tmp, cacheContentDir)
+	if _, err = s.fetch(ctx, content); err != nil {
+		return "", err
+	}
+
+	entry := CacheEntry{Source: s.URL, Ref: s.Ref, FetchedAt: time.Now()}
+	if s.Git {
+		if entry.Revision, err = outputGit(ctx, content, "rev-parse", "HEAD"); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(filepath.Join(tmp, cacheEntryFile), data, 0644); err != nil {
+		return "", err
+	}
+
+	if err = os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+	return s.localPath(filepath.Join(dir, cacheContentDir))
+}
+
+// List returns every cached workflow, sorted by source.
+func (c Cache) List() ([]CacheEntry, error) {
+	dirs, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CacheEntry
+	for _, d := range dirs {
+		path := filepath.Join(c.Dir, d.Name(), cacheEntryFile)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// an interrupted fetch
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry := CacheEntry{Dir: filepath.Join(c.Dir, d.Name())}
+		if err = json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to read cache entry (%s): %w", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Source == entries[j].Source {
+			return entries[i].Ref < entries[j].Ref
+		}
+		return entries[i].Source < entries[j].Source
+	})
+	return entries, nil
+}
+
+// Clean removes every cached workflow.
+func (c Cache) Clean() error {
+	return os.RemoveAll(c.Dir)
+}
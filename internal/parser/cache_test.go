@@ -0,0 +1,60 @@
+package parser_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestCache(t *testing.T) {
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/workflows/go.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(remoteWorkflow))
+	})
+	mux.HandleFunc("/workflows/templates/main.go.tmpl", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("package main"))
+	})
+	server := httptest.NewServer(mux)
+	url := server.URL + "/workflows/go.yaml"
+
+	cache := &parser.Cache{Dir: t.TempDir()}
+
+	got, err := parser.NewParser().WithCache(cache).Parse(url)
+	td.Require(t).CmpNoError(err)
+	assertTemplate(t, got, "package main")
+
+	entries, err := cache.List()
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, entries, td.Bag(
+		td.SStruct(parser.CacheEntry{Source: url}, td.StructFields{
+			"FetchedAt": td.NotZero(),
+			"Dir":       td.HasPrefix(cache.Dir),
+		}),
+	))
+
+	// offline, the cached copy is used
+	server.Close()
+	got, err = parser.NewParser().WithCache(cache).Parse(url)
+	td.Require(t).CmpNoError(err)
+	assertTemplate(t, got, "package main")
+	td.Cmp(t, hits.Load(), int32(1))
+
+	// refreshing needs the server, the cached copy is left untouched
+	cache.Refresh = true
+	_, err = parser.NewParser().WithCache(cache).Parse(url)
+	td.CmpContains(t, err, "failed to fetch file")
+	entries, err = cache.List()
+	td.CmpNoError(t, err)
+	td.CmpLen(t, entries, 1)
+
+	td.CmpNoError(t, cache.Clean())
+	entries, err = cache.List()
+	td.CmpNoError(t, err)
+	td.CmpEmpty(t, entries)
+}
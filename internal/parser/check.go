@@ -0,0 +1,441 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// builtinModules are the modules shipped with boot, they are suggested for misspelled module names
+// along with the installed modules given to [Parser.WithModules].
+var builtinModules = []string{"license"}
+
+// checkedLists are the lists of the workflow whose items are validated one by one: cue hides
+// missing required fields once another error is found, checking items on their own reports them too.
+var checkedLists = []string{"vars", "steps", "folder_struct"}
+
+// A CheckError is a single problem found in a workflow file.
+type CheckError struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	// Path locates the offending value, e.g. steps[2].action.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Expected lists the accepted values, when there is a finite set of them.
+	Expected   []string `json:"expected,omitempty"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// Error implements the [error] interface.
+func (c CheckError) Error() string {
+	var sb strings.Builder
+	switch {
+	case c.Line > 0:
+		fmt.Fprintf(&sb, "%s:%d:%d: ", c.Filename, c.Line, c.Column)
+	case c.Filename != "":
+		fmt.Fprintf(&sb, "%s: ", c.Filename)
+	}
+	if c.Path != "" {
+		fmt.Fprintf(&sb, "%s: ", c.Path)
+	}
+	sb.WriteString(c.Message)
+	switch len(c.Expected) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&sb, ", expected %s", c.Expected[0])
+	default:
+		fmt.Fprintf(&sb, ", expected one of %s", strings.Join(c.Expected, ", "))
+	}
+	if c.Suggestion != "" {
+		fmt.Fprintf(&sb, " (%s)", c.Suggestion)
+	}
+	return sb.String()
+}
+
+// CheckErrors holds every problem found in a workflow file, sorted by position.
+type CheckErrors []CheckError
+
+// Error implements the [error] interface.
+func (c CheckErrors) Error() string {
+	lines := make([]string, len(c))
+	for i, err := range c {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AsCheckErrors converts any error met while reading or checking filename into [CheckErrors],
+// so that every problem can be reported the same way.
+func AsCheckErrors(filename string, err error) CheckErrors {
+	var checkErrs CheckErrors
+	if errors.As(err, &checkErrs) {
+		return checkErrs
+	}
+
+	var res CheckErrors
+	for _, e := range errors.Errors(err) {
+		checkErr := CheckError{Filename: filename, Message: e.Error()}
+		if pos := e.Position(); pos.Line() > 0 {
+			checkErr.Line, checkErr.Column = pos.Line(), pos.Column()
+			format, args := e.Msg()
+			checkErr.Message = fmt.Sprintf(format, args...)
+		}
+		res = append(res, checkErr)
+	}
+	if len(res) == 0 && err != nil {
+		res = append(res, CheckError{Filename: filename, Message: err.Error()})
+	}
+	return res
+}
+
+// Check validates value against the schema of the version it is written in. Every problem is reported at once in [CheckErrors].
+func (p Parser) Check(ctx *cue.Context, value cue.Value) error {
+	version, err := WorkflowVersion(value)
+	if err != nil {
+		return newVersionError(value)
+	}
+	schemaSource, err := versionSchema(version)
+	if err != nil {
+		return err
+	}
+	schema := ctx.CompileString(schemaSource).LookupPath(cue.ParsePath("#Workflow"))
+	unified := schema.Unify(value)
+
+	cueErrs := errors.Errors(unified.Validate(cue.Concrete(true)))
+	for _, list := range checkedLists {
+		length, err := value.LookupPath(cue.ParsePath(list)).Len().Int64()
+		if err != nil {
+			continue
+		}
+		for i := range int(length) {
+			item := unified.LookupPath(cue.MakePath(cue.Str(list), cue.Index(i)))
+			cueErrs = append(cueErrs, errors.Errors(item.Validate(cue.Concrete(true)))...)
+		}
+	}
+	modules := slices.Concat(builtinModules, p.modules)
+	var res CheckErrors
+	if len(cueErrs) > 0 {
+		res = newCheckErrors(value, cueErrs, modules)
+	}
+	res = append(res, misspelledModules(value, modules)...)
+	if len(res) == 0 {
+		return nil
+	}
+	sortByPosition(res)
+	return res
+}
+
+// newVersionError reports an unsupported version field.
+func newVersionError(value cue.Value) CheckErrors {
+	version := value.LookupPath(cue.ParsePath("version"))
+	checkErr := CheckError{Path: "version", Message: fmt.Sprintf("unsupported version %v", version)}
+	for v := range CurrentVersion {
+		checkErr.Expected = append(checkErr.Expected, strconv.Itoa(v+1))
+	}
+	if pos := valuePosition(version); pos.Line() > 0 {
+		checkErr.Filename, checkErr.Line, checkErr.Column = pos.Filename(), pos.Line(), pos.Column()
+	}
+	return CheckErrors{checkErr}
+}
+
+// newCheckErrors converts cue errors into [CheckErrors]. Cue reports every failed branch of a disjunction:
+// only the deepest errors are kept, and conflicts on the same value are merged into a list of expected values.
+// modules are the known modules, suggested for misspelled module names.
+func newCheckErrors(value cue.Value, cueErrs []errors.Error, modules []string) CheckErrors {
+	var (
+		seen   = make(map[string]bool)
+		paths  []string
+		byPath = make(map[string][]errors.Error)
+	)
+	for _, e := range cueErrs {
+		format, _ := e.Msg()
+		if strings.HasSuffix(format, "errors in empty disjunction:") || seen[e.Error()] {
+			continue
+		}
+		seen[e.Error()] = true
+		path := strings.Join(trimSchemaPath(e.Path()), ".")
+		if _, ok := byPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		byPath[path] = append(byPath[path], e)
+	}
+
+	var res CheckErrors
+	for _, path := range paths {
+		if hasDeeperPath(path, paths) {
+			continue
+		}
+		res = append(res, newCheckError(value, byPath[path], modules)...)
+	}
+	sortByPosition(res)
+	return res
+}
+
+// sortByPosition sorts problems by their position in the workflow file.
+func sortByPosition(res CheckErrors) {
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Line == res[j].Line {
+			return res[i].Column < res[j].Column
+		}
+		return res[i].Line < res[j].Line
+	})
+}
+
+// newCheckError converts the cue errors found on a single path.
+func newCheckError(value cue.Value, cueErrs []errors.Error, modules []string) CheckErrors {
+	selectors := trimSchemaPath(cueErrs[0].Path())
+	userValue, found := lookupUserValue(value, selectors)
+
+	base := CheckError{Path: formatPath(selectors)}
+	pos := userPosition(value, cueErrs, userValue)
+	if pos.Line() > 0 {
+		base.Filename, base.Line, base.Column = pos.Filename(), pos.Line(), pos.Column()
+	}
+
+	var (
+		res      CheckErrors
+		expected []string
+		got      = fmt.Sprint(userValue)
+	)
+	for _, e := range cueErrs {
+		format, args := e.Msg()
+		if !found && strings.HasPrefix(format, "incomplete value") && len(args) > 0 {
+			// a field without default value in the schema, that the workflow does not set
+			checkErr := base
+			checkErr.Message = "field is required but not present"
+			checkErr.Expected = []string{fmt.Sprint(args[0])}
+			res = append(res, checkErr)
+			continue
+		}
+		if !strings.HasPrefix(format, "conflicting values %s and %s") || !found {
+			checkErr := base
+			checkErr.Message = fmt.Sprintf(format, args...)
+			checkErr.Suggestion = suggestModule(value, selectors, format, modules)
+			res = append(res, checkErr)
+			continue
+		}
+
+		// one side is the user value, the other one what the schema expects
+		other, otherIdx := fmt.Sprint(args[0]), 0
+		if other == got {
+			other, otherIdx = fmt.Sprint(args[1]), 1
+		}
+		if len(args) == 4 {
+			// mismatched types, the kind is more readable than the schema definition
+			other = fmt.Sprint(args[2+otherIdx])
+		}
+		if unquoted, err := strconv.Unquote(other); err == nil {
+			other = unquoted
+		}
+		if !slices.Contains(expected, other) {
+			expected = append(expected, other)
+		}
+	}
+
+	if len(expected) > 0 {
+		checkErr := base
+		checkErr.Message = "invalid value " + got
+		sort.Strings(expected)
+		checkErr.Expected = expected
+		if s, err := userValue.String(); err == nil {
+			if closest := closestWord(s, expected); closest != "" {
+				checkErr.Suggestion = fmt.Sprintf("did you mean %q?", closest)
+			}
+		}
+		res = append(res, checkErr)
+	}
+	return res
+}
+
+// trimSchemaPath removes the #Workflow definition from the path of a cue error.
+func trimSchemaPath(path []string) []string {
+	if len(path) > 0 && path[0] == "#Workflow" {
+		return path[1:]
+	}
+	return path
+}
+
+// hasDeeperPath checks that an error has been found under path.
+func hasDeeperPath(path string, paths []string) bool {
+	for _, other := range paths {
+		if other != path && (path == "" || strings.HasPrefix(other, path+".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatPath writes selectors the way they are written in a workflow: steps[2].action.
+func formatPath(selectors []string) string {
+	var sb strings.Builder
+	for _, sel := range selectors {
+		if _, err := strconv.Atoi(sel); err == nil {
+			fmt.Fprintf(&sb, "[%s]", sel)
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(sel)
+	}
+	return sb.String()
+}
+
+// lookupUserValue returns the value written in the workflow at selectors.
+func lookupUserValue(value cue.Value, selectors []string) (cue.Value, bool) {
+	sels := make([]cue.Selector, len(selectors))
+	for i, sel := range selectors {
+		if index, err := strconv.Atoi(sel); err == nil {
+			sels[i] = cue.Index(index)
+			continue
+		}
+		if unquoted, err := strconv.Unquote(sel); err == nil {
+			sel = unquoted
+		}
+		sels[i] = cue.Str(sel)
+	}
+	v := value.LookupPath(cue.MakePath(sels...))
+	return v, v.Exists()
+}
+
+// userPosition returns the position of the error in the workflow file. A missing field is located at its parent.
+func userPosition(value cue.Value, cueErrs []errors.Error, userValue cue.Value) token.Pos {
+	for _, e := range cueErrs {
+		for _, pos := range e.InputPositions() {
+			if pos.Filename() != "" {
+				return pos
+			}
+		}
+	}
+	if userValue.Exists() {
+		return valuePosition(userValue)
+	}
+	for _, e := range cueErrs {
+		selectors := trimSchemaPath(e.Path())
+		for i := len(selectors) - 1; i >= 0; i-- {
+			if parent, ok := lookupUserValue(value, selectors[:i]); ok {
+				if pos := valuePosition(parent); pos.Line() > 0 {
+					return pos
+				}
+			}
+		}
+	}
+	return token.NoPos
+}
+
+// valuePosition returns the position of v, or the one of its first field for structs without braces (yaml).
+func valuePosition(v cue.Value) token.Pos {
+	if pos := v.Pos(); pos.Line() > 0 {
+		return pos
+	}
+	iter, err := v.Fields()
+	if err == nil && iter.Next() {
+		return iter.Value().Pos()
+	}
+	return token.NoPos
+}
+
+// suggestModule suggests one of modules when a step misses its action because its module name is misspelled.
+func suggestModule(value cue.Value, selectors []string, format string, modules []string) string {
+	if len(selectors) != 3 || selectors[0] != "steps" || selectors[2] != "action" || !strings.Contains(format, "field is required") {
+		return ""
+	}
+	step, _ := lookupUserValue(value, selectors[:2])
+	module, err := step.LookupPath(cue.ParsePath("module")).String()
+	if err != nil {
+		return ""
+	}
+	if closest := closestWord(module, modules); closest != "" {
+		return fmt.Sprintf("did you mean module %q?", closest)
+	}
+	return ""
+}
+
+// misspelledModules reports the steps with an action whose module name looks like a typo of one of modules.
+// A module far from all of them may be installed later, lint reports it.
+func misspelledModules(value cue.Value, modules []string) CheckErrors {
+	length, err := value.LookupPath(cue.ParsePath("steps")).Len().Int64()
+	if err != nil {
+		return nil
+	}
+	var res CheckErrors
+	for i := range int(length) {
+		selectors := []string{"steps", strconv.Itoa(i)}
+		step, _ := lookupUserValue(value, selectors)
+		if !step.LookupPath(cue.ParsePath("action")).Exists() {
+			// the missing action is reported with the suggestion
+			continue
+		}
+		moduleValue := step.LookupPath(cue.ParsePath("module"))
+		module, err := moduleValue.String()
+		if err != nil {
+			continue
+		}
+		closest := closestWord(module, modules)
+		if closest == "" {
+			continue
+		}
+		checkErr := CheckError{
+			Path:       formatPath(append(selectors, "module")),
+			Message:    fmt.Sprintf("unknown module %q", module),
+			Suggestion: fmt.Sprintf("did you mean module %q?", closest),
+		}
+		pos := valuePosition(moduleValue)
+		if field, ok := moduleValue.Source().(*ast.Field); ok && field.Value.Pos().Line() > 0 {
+			// the position of the value, not of the field
+			pos = field.Value.Pos()
+		}
+		if pos.Line() > 0 {
+			checkErr.Filename, checkErr.Line, checkErr.Column = pos.Filename(), pos.Line(), pos.Column()
+		}
+		res = append(res, checkErr)
+	}
+	return res
+}
+
+// closestWord returns the candidate closest to word, or an empty string when none of them looks like a typo.
+func closestWord(word string, candidates []string) string {
+	var (
+		best     string
+		bestDist = max(2, len(word)/3) + 1
+	)
+	for _, candidate := range candidates {
+		if candidate == word {
+			return ""
+		}
+		if dist := levenshtein(strings.ToLower(word), strings.ToLower(candidate)); dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// levenshtein computes the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+		}
+		prev = current
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,121 @@
+package parser_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestParser_Check(t *testing.T) {
+	const filename = "../mocks/workflow_invalid.yaml"
+
+	ctx := cuecontext.New()
+	value, err := helper.CueUnmarshalFile(ctx, filename)
+	td.Require(t).CmpNoError(err)
+
+	err = parser.NewParser().Check(ctx, *value)
+	td.Cmp(t, err, parser.CheckErrors{
+		{
+			Filename: filename, Line: 6, Column: 11,
+			Path:       "vars[0].type",
+			Message:    `invalid value "strin"`,
+			Expected:   []string{"bool", "computed", "int", "license", "list", "multi", "number", "password", "select", "string"},
+			Suggestion: `did you mean "string"?`,
+		},
+		{
+			Filename: filename, Line: 10, Column: 13,
+			Path:    "steps[0].action",
+			Message: `invalid value "int"`,
+			Expected: []string{
+				"add", "addOrigin", "applyTemplate", "commit", "createFile", "createFolder", "createFolderStruct",
+				"init", "installDevDeps", "installGlobalDeps", "installLocalDeps", "push", "writeFile",
+			},
+			Suggestion: `did you mean "init"?`,
+		},
+		{
+			Filename: filename, Line: 11, Column: 5,
+			Path:       "steps[1].action",
+			Message:    "field is required but not present",
+			Suggestion: `did you mean module "license"?`,
+		},
+		{
+			Filename: filename, Line: 16, Column: 5,
+			Path:    "steps[2].extra",
+			Message: "field not allowed",
+		},
+		{
+			Filename: filename, Line: 19, Column: 5,
+			Path:    "steps[3].action",
+			Message: "field not allowed",
+		},
+		{
+			Filename: filename, Line: 21, Column: 5,
+			Path:     "folder_struct[0]",
+			Message:  "invalid value 123",
+			Expected: []string{"string", "struct"},
+		},
+		{
+			Filename: filename, Line: 23, Column: 7,
+			Path:    `folder_struct[1]."x.go".foo`,
+			Message: "field not allowed",
+		},
+		{
+			Filename: filename, Line: 25, Column: 7,
+			Path:     `folder_struct[2]."main.go".template.engine`,
+			Message:  "field is required but not present",
+			Expected: []string{"string"},
+		},
+	})
+
+	td.CmpString(t, err.(parser.CheckErrors)[2],
+		`../mocks/workflow_invalid.yaml:11:5: steps[1].action: field is required but not present (did you mean module "license"?)`)
+}
+
+func TestParser_CheckModules(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+steps:
+  - {name: commit, module: gti, action: commit}
+  - {name: install, module: yarn, action: installLocalDeps}
+  - {name: license, module: licence}
+  - {name: push, module: git, action: push}
+`,
+	})
+	filename := filepath.Join(dir, "workflow.yaml")
+
+	ctx := cuecontext.New()
+	value, err := helper.CueUnmarshalFile(ctx, filename)
+	td.Require(t).CmpNoError(err)
+
+	err = parser.NewParser().WithModules([]string{"git", "npm"}).Check(ctx, *value)
+	td.Cmp(t, err, parser.CheckErrors{
+		{
+			Filename: filename, Line: 3, Column: 28,
+			Path:       "steps[0].module",
+			Message:    `unknown module "gti"`,
+			Suggestion: `did you mean module "git"?`,
+		},
+		{
+			Filename: filename, Line: 5, Column: 6,
+			Path:       "steps[2].action",
+			Message:    "field is required but not present",
+			Suggestion: `did you mean module "license"?`,
+		},
+	})
+
+	// without the installed modules, only the builtin ones are suggested
+	err = parser.NewParser().Check(ctx, *value)
+	td.Cmp(t, err, parser.CheckErrors{
+		{
+			Filename: filename, Line: 5, Column: 6,
+			Path:       "steps[2].action",
+			Message:    "field is required but not present",
+			Suggestion: `did you mean module "license"?`,
+		},
+	})
+}
@@ -0,0 +1,446 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bootengine/boot/internal/expr"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/log"
+)
+
+// ErrIncludeCycle is returned when a workflow includes itself, directly or through other workflows.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// An IncludeError is an error met while resolving the includes of a workflow.
+type IncludeError struct {
+	// Chain lists the workflows from the parsed one to the one that failed.
+	Chain []string
+	Err   error
+}
+
+// Error implements the [error] interface.
+func (e IncludeError) Error() string {
+	return fmt.Sprintf("failed to resolve includes %s: %s", strings.Join(e.Chain, " -> "), e.Err)
+}
+
+func (e IncludeError) Unwrap() error {
+	return e.Err
+}
+
+// parse parses filename and merges the workflows it includes, recursively. chain lists the
+// workflows including filename.
+func (p *Parser) parse(filename string, chain []string) (*model.Workflow, error) {
+	chain = append(slices.Clip(chain), filename)
+	if slices.ContainsFunc(chain[:len(chain)-1], func(including string) bool {
+		return includeKey(including) == includeKey(filename)
+	}) {
+		return nil, IncludeError{Chain: chain, Err: ErrIncludeCycle}
+	}
+
+	workflow, local, err := p.parseFile(filename)
+	if err != nil {
+		return nil, includeError(chain, err)
+	}
+	if len(chain) > 1 && !FilenameIsURL(filename) {
+		includedTemplates(workflow.FolderStruct, filepath.Dir(local))
+	}
+
+	included := make(map[string]*model.Workflow, len(workflow.Config.Includes))
+	for _, include := range workflow.Config.Includes {
+		if _, ok := included[include.As]; ok {
+			return nil, IncludeError{Chain: chain, Err: fmt.Errorf("include alias %q is used twice", include.As)}
+		}
+		from, err := includePath(filename, local, include.From)
+		if err != nil {
+			return nil, IncludeError{Chain: chain, Err: err}
+		}
+		if included[include.As], err = p.parse(from, chain); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = mergeIncludes(workflow, included); err != nil {
+		return nil, IncludeError{Chain: chain, Err: err}
+	}
+	// the steps of a hook only need each other
+	for _, steps := range [][]model.Step{workflow.Steps, workflow.Hooks.PreGen, workflow.Hooks.PostGen, workflow.Hooks.OnFailure} {
+		if _, err = model.StepDependencies(steps); err != nil {
+			return nil, includeError(chain, ParserError{action: "check", err: err, filename: filename})
+		}
+	}
+	return workflow, nil
+}
+
+// includedTemplates makes the relative template paths of a local included workflow relative to dir,
+// its directory, as they are for a remote workflow.
+func includedTemplates(fs model.FolderStruct, dir string) {
+	_ = mapTemplatePaths(fs, func(rel string) (string, error) {
+		return filepath.Join(dir, filepath.FromSlash(rel)), nil
+	})
+}
+
+// mapTemplatePaths replaces every relative template path of fs with the path resolve returns for it.
+func mapTemplatePaths(fs model.FolderStruct, resolve func(rel string) (string, error)) error {
+	for _, f := range fs {
+		if !f.IsFile() {
+			if err := mapTemplatePaths(f.(model.Folder).Filers, resolve); err != nil {
+				return err
+			}
+			continue
+		}
+		file := f.(model.File)
+		if file.TempWrapper == nil || file.Filepath == "" || filepath.IsAbs(file.Filepath) {
+			continue
+		}
+		local, err := resolve(file.Filepath)
+		if err != nil {
+			return err
+		}
+		// TempWrapper is a pointer, the workflow sees the new path
+		file.Filepath = local
+	}
+	return nil
+}
+
+// includeError wraps the error met while parsing an included workflow with the include chain.
+func includeError(chain []string, err error) error {
+	if len(chain) == 1 {
+		return err
+	}
+	return IncludeError{Chain: chain, Err: err}
+}
+
+// includeKey identifies a workflow file, to detect include cycles.
+func includeKey(filename string) string {
+	if FilenameIsURL(filename) {
+		return filename
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		return abs
+	}
+	return filepath.Clean(filename)
+}
+
+// includePath resolves the path of an included workflow relative to the including one: next to the file for local
+// workflows, relative to the url or inside the same repository and ref for remote ones.
+func includePath(including, local, from string) (string, error) {
+	if FilenameIsURL(from) || filepath.IsAbs(from) {
+		return from, nil
+	}
+	if !FilenameIsURL(including) {
+		return filepath.Join(filepath.Dir(local), filepath.FromSlash(from)), nil
+	}
+
+	remote, err := ParseRemoteSource(including)
+	if err != nil {
+		return "", err
+	}
+	if remote.Git {
+		remote.Path = path.Clean("/" + path.Join(path.Dir(remote.Path), filepath.ToSlash(from)))[1:]
+		return remote.String(), nil
+	}
+	base, err := url.Parse(remote.URL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(filepath.ToSlash(from))
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// mergeIncludes merges the included workflows by alias: their vars are added before the ones of the
+// workflow when not declared yet, after them when the include gives values `with` the vars of the
+// workflow. Their steps are added after the ones of the workflow, scoped to the $alias folder and with
+// their ids prefixed by the alias, and their folder_struct replaces the $alias folder.
+func mergeIncludes(workflow *model.Workflow, included map[string]*model.Workflow) error {
+	if len(workflow.Config.Includes) == 0 {
+		if alias, ok := findAlias(workflow.FolderStruct); ok {
+			return fmt.Errorf("folder $%s has no matching include", alias)
+		}
+		return nil
+	}
+
+	aliasPaths := make(map[string]string)
+	parseFolder(workflow.FolderStruct, aliasPaths, "")
+
+	var before, after model.Vars
+	for _, include := range workflow.Config.Includes {
+		work := included[include.As]
+		if err := bindInclude(work, include, workflow.Vars); err != nil {
+			return err
+		}
+
+		vars := &before
+		if len(include.With) > 0 {
+			vars = &after
+		}
+		for _, v := range work.Vars {
+			if !slices.ContainsFunc(workflow.Vars, func(elem model.Var) bool { return elem.Name == v.Name }) &&
+				!slices.ContainsFunc(before, func(elem model.Var) bool { return elem.Name == v.Name }) &&
+				!slices.ContainsFunc(after, func(elem model.Var) bool { return elem.Name == v.Name }) {
+				*vars = append(*vars, v)
+			}
+		}
+
+		// the folder_struct of the included workflow is created with the one of the including workflow
+		for _, s := range work.Steps {
+			if s.Action != model.CreateFolderStructAction {
+				s.Scope = filepath.Join(aliasPaths[include.As], s.Scope)
+				s.ID, s.Needs = includedStepIDs(include.As, s.ID, s.Needs)
+				if err := includedStepOutputs(include.As, &s); err != nil {
+					return fmt.Errorf("include %s: step %s: %w", include.As, s.Name, err)
+				}
+				workflow.Steps = append(workflow.Steps, s)
+			}
+		}
+
+		if len(work.Hooks.PreGen)+len(work.Hooks.PostGen)+len(work.Hooks.OnFailure) > 0 {
+			log.Warn("the hooks of an included workflow are ignored, only the ones of the generated workflow run", "from", include.From)
+		}
+		if _, ok := aliasPaths[include.As]; !ok && len(work.FolderStruct) > 0 {
+			log.Warn("the folder_struct of an included workflow is ignored, add a $"+include.As+" folder to create it", "from", include.From)
+		}
+	}
+	workflow.Vars = slices.Concat(before, workflow.Vars, after)
+
+	folderStructs := make(map[string]model.FolderStruct, len(included))
+	for alias, work := range included {
+		folderStructs[alias] = work.FolderStruct
+	}
+	workflow.FolderStruct = mergeFolderStruct(workflow.FolderStruct, folderStructs)
+	if alias, ok := findAlias(workflow.FolderStruct); ok {
+		return fmt.Errorf("folder $%s has no matching include", alias)
+	}
+	return nil
+}
+
+// bindInclude applies the namespace and the values given `with` an include to the included workflow.
+// declared are the vars of the including workflow.
+func bindInclude(work *model.Workflow, include model.Include, declared model.Vars) error {
+	if include.Namespace {
+		if err := namespaceWorkflow(work, include.As); err != nil {
+			return fmt.Errorf("include %s: %w", include.As, err)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(include.With)) {
+		i := slices.IndexFunc(work.Vars, func(v model.Var) bool { return v.Name == namespaced(include, name) })
+		switch {
+		case i < 0:
+			return fmt.Errorf("include %s: with.%s is not a var of %s", include.As, name, include.From)
+		case work.Vars[i].Type == model.Computed:
+			return fmt.Errorf("include %s: with.%s is a computed var, it can't be given a value", include.As, name)
+		case slices.ContainsFunc(declared, func(v model.Var) bool { return v.Name == work.Vars[i].Name }):
+			return fmt.Errorf("include %s: with.%s is also a var of the including workflow, use a namespace", include.As, name)
+		}
+		work.Vars[i].Value = include.With[name]
+	}
+	return nil
+}
+
+// includedStepIDs prefixes the id and needs of an included step with the alias of the include,
+// so that a workflow included twice has distinct step ids.
+func includedStepIDs(alias, id string, needs *[]string) (string, *[]string) {
+	if id != "" {
+		id = alias + "." + id
+	}
+	if needs == nil {
+		return id, nil
+	}
+	res := make([]string, len(*needs))
+	for i, need := range *needs {
+		res[i] = alias + "." + need
+	}
+	return id, &res
+}
+
+// includedStepOutputs prefixes with the alias of the include the ids of the steps whose outputs,
+// steps.<id>.outputs, are used by an included step, like [includedStepIDs] does for their ids.
+func includedStepOutputs(alias string, step *model.Step) error {
+	return renameStepOutputs(step, func(name string) string {
+		if rest, ok := strings.CutPrefix(name, "steps."); ok {
+			return "steps." + alias + "." + rest
+		}
+		return name
+	})
+}
+
+// renameStepOutputs renames the vars used by the expressions of step that can refer to step outputs.
+func renameStepOutputs(step *model.Step, rename func(string) string) error {
+	var err error
+	if step.When, err = expr.Rename(step.When, rename); err != nil {
+		return err
+	}
+	if step.CurrentWorkingDir, err = expr.RenameTemplate(step.CurrentWorkingDir, rename); err != nil {
+		return err
+	}
+	if step.Params != nil {
+		params := make([]string, len(step.Params))
+		for i, param := range step.Params {
+			if params[i], err = expr.RenameTemplate(param, rename); err != nil {
+				return err
+			}
+		}
+		step.Params = params
+	}
+	return nil
+}
+
+// namespaced returns the name of the var name of an included workflow once included.
+func namespaced(include model.Include, name string) string {
+	if include.Namespace {
+		return include.As + "." + name
+	}
+	return name
+}
+
+// namespaceWorkflow renames the vars of work to namespace.varname, in their declaration and in
+// every expression of the workflow.
+func namespaceWorkflow(work *model.Workflow, namespace string) error {
+	names := make(map[string]bool, len(work.Vars))
+	for _, v := range work.Vars {
+		names[v.Name] = true
+	}
+	rename := func(name string) string {
+		if names[name] {
+			return namespace + "." + name
+		}
+		return name
+	}
+
+	var err error
+	for i, v := range work.Vars {
+		v.Name = rename(v.Name)
+		if v.When, err = expr.Rename(v.When, rename); err != nil {
+			return fmt.Errorf("var %s: %w", v.Name, err)
+		}
+		if v.Expr, err = expr.RenameTemplate(v.Expr, rename); err != nil {
+			return fmt.Errorf("var %s: %w", v.Name, err)
+		}
+		if value, ok := v.Value.(string); ok {
+			if v.Value, err = expr.RenameTemplate(value, rename); err != nil {
+				return fmt.Errorf("var %s: %w", v.Name, err)
+			}
+		}
+		work.Vars[i] = v
+	}
+	for i, s := range work.Steps {
+		if s.When, err = expr.Rename(s.When, rename); err != nil {
+			return fmt.Errorf("step %s: %w", s.Name, err)
+		}
+		if s.CurrentWorkingDir, err = expr.RenameTemplate(s.CurrentWorkingDir, rename); err != nil {
+			return fmt.Errorf("step %s: %w", s.Name, err)
+		}
+		if s.Name, err = expr.RenameTemplate(s.Name, rename); err != nil {
+			return fmt.Errorf("step %s: %w", s.Name, err)
+		}
+		params := make([]string, len(s.Params))
+		for j, param := range s.Params {
+			if params[j], err = expr.RenameTemplate(param, rename); err != nil {
+				return fmt.Errorf("step %s: %w", s.Name, err)
+			}
+		}
+		if s.Params != nil {
+			s.Params = params
+		}
+		work.Steps[i] = s
+	}
+	work.FolderStruct, err = namespaceFolderStruct(work.FolderStruct, namespace, rename)
+	return err
+}
+
+// namespaceFolderStruct renames the vars of the names and conditions of fs, and namespaces its templates.
+func namespaceFolderStruct(fs model.FolderStruct, namespace string, rename func(string) string) (model.FolderStruct, error) {
+	res := make(model.FolderStruct, 0, len(fs))
+	for _, f := range fs {
+		when, err := expr.Rename(f.GetWhen(), rename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.GetName(), err)
+		}
+		name, err := expr.RenameTemplate(f.GetName(), rename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.GetName(), err)
+		}
+		if f.IsFile() {
+			file := f.(model.File)
+			file.Name, file.When = name, when
+			if file.TempWrapper != nil {
+				tmpl := *file.TempWrapper
+				tmpl.Namespace = strings.Trim(namespace+"."+tmpl.Namespace, ".")
+				file.TempWrapper = &tmpl
+			}
+			res = append(res, file)
+			continue
+		}
+		folder := f.(model.Folder)
+		folder.Name, folder.When = name, when
+		if folder.Filers, err = namespaceFolderStruct(folder.Filers, namespace, rename); err != nil {
+			return nil, err
+		}
+		res = append(res, folder)
+	}
+	return res, nil
+}
+
+// parseFolder maps the name of every $alias folder to its path.
+func parseFolder(fs model.FolderStruct, pathMap map[string]string, currentPath string) {
+	for _, fd := range fs {
+		if fd.IsFile() {
+			continue
+		}
+		if strings.HasPrefix(fd.GetName(), "$") {
+			pathMap[strings.TrimPrefix(fd.GetName(), "$")] = currentPath
+			continue
+		}
+		parseFolder(fd.(model.Folder).Filers, pathMap, filepath.Join(currentPath, fd.GetName()))
+	}
+}
+
+// mergeFolderStruct replaces the $alias folders with the folder_struct of the matching included workflow.
+func mergeFolderStruct(fs model.FolderStruct, content map[string]model.FolderStruct) model.FolderStruct {
+	if len(fs) == 0 {
+		return fs
+	}
+	res := make(model.FolderStruct, 0, len(fs))
+	for _, f := range fs {
+		if f.IsFile() {
+			res = append(res, f)
+			continue
+		}
+		if name, ok := strings.CutPrefix(f.GetName(), "$"); ok {
+			if merging, ok := content[name]; ok {
+				res = append(res, merging...)
+				continue
+			}
+		}
+		current := f.(model.Folder)
+		current.Filers = mergeFolderStruct(current.Filers, content)
+		res = append(res, current)
+	}
+	return res
+}
+
+// findAlias returns the name of the first $alias folder of fs.
+func findAlias(fs model.FolderStruct) (string, bool) {
+	for _, f := range fs {
+		if f.IsFile() {
+			continue
+		}
+		if alias, ok := strings.CutPrefix(f.GetName(), "$"); ok {
+			return alias, true
+		}
+		if alias, ok := findAlias(f.(model.Folder).Filers); ok {
+			return alias, true
+		}
+	}
+	return "", false
+}
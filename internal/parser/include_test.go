@@ -0,0 +1,338 @@
+package parser_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// writeWorkflows writes the workflow files in dir, by relative path.
+func writeWorkflows(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		td.Require(t).CmpNoError(os.MkdirAll(filepath.Dir(filename), 0755))
+		td.Require(t).CmpNoError(os.WriteFile(filename, []byte(content), 0644))
+	}
+}
+
+func TestParser_ParseIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+config:
+  includes:
+    - from: services/api.yaml
+      as: api
+vars:
+  - name: project_name
+    type: string
+steps:
+  - name: git init
+    module: git
+    action: init
+folder_struct:
+  - main.go
+  - services:
+    - $api
+`,
+		"services/api.yaml": `version: 2
+config:
+  includes:
+    - from: ../shared/db.yaml
+      as: db
+vars:
+  - name: project_name
+    type: string
+  - name: api_port
+    type: int
+steps:
+  - name: go mod init
+    module: go
+    action: init
+folder_struct:
+  - api.go
+  - store:
+    - $db
+`,
+		"shared/db.yaml": `version: 2
+vars:
+  - name: db_name
+    type: string
+steps:
+  - name: migrate init
+    id: init
+    module: migrate
+    action: init
+    cwd: migrations
+  - name: migrate create
+    module: migrate
+    action: createFile
+    needs: [init]
+    params: ["{{ steps.init.outputs.version }}_create.sql"]
+folder_struct:
+  - schema.sql
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Vars, model.Vars{
+		{Name: "db_name", Type: model.String},
+		{Name: "api_port", Type: model.Int},
+		{Name: "project_name", Type: model.String},
+	})
+	td.Cmp(t, got.Steps, []model.Step{
+		{Name: "git init", Module: "git", Action: model.InitAction},
+		{Name: "go mod init", Module: "go", Action: model.InitAction, Scope: "services"},
+		{Name: "migrate init", ID: "api.db.init", Module: "migrate", Action: model.InitAction, CurrentWorkingDir: "migrations", Scope: "services/store"},
+		{Name: "migrate create", Needs: &[]string{"api.db.init"}, Module: "migrate", Action: model.CreateFileAction, Scope: "services/store",
+			Params: []string{"{{ steps.api.db.init.outputs.version }}_create.sql"}},
+	})
+	td.Cmp(t, got.FolderStruct, model.FolderStruct{
+		model.File{Name: "main.go"},
+		model.Folder{Name: "services", Filers: model.FolderStruct{
+			model.File{Name: "api.go"},
+			model.Folder{Name: "store", Filers: model.FolderStruct{
+				model.File{Name: "schema.sql"},
+			}},
+		}},
+	})
+}
+
+func TestParser_ParseIncludesWith(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+config:
+  includes:
+    - from: go-service.yaml
+      as: api
+      namespace: true
+      with:
+        service_name: "{{ project_name }}-api"
+        port: 8080
+    - from: go-service.yaml
+      as: worker
+      namespace: true
+vars:
+  - name: project_name
+    type: string
+folder_struct:
+  - api:
+    - $api
+  - worker:
+    - $worker
+`,
+		"go-service.yaml": `version: 2
+vars:
+  - name: service_name
+    type: string
+  - name: port
+    type: int
+  - name: module_path
+    type: computed
+    expr: "{{ service_name | kebab }}"
+    when: port > 0
+steps:
+  - name: go mod init
+    module: go
+    action: init
+    cwd: "{{ service_name }}"
+    params: ["{{ module_path }}"]
+    when: service_name != ""
+folder_struct:
+  - main.go:
+      template: {engine: gotmpl, filepath: main.tmpl}
+  - "{{ service_name }}.env"
+  - docker:
+      when: port
+      children: [compose.yaml]
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Vars, model.Vars{
+		{Name: "worker.service_name", Type: model.String},
+		{Name: "worker.port", Type: model.Int},
+		{Name: "worker.module_path", Type: model.Computed, Expr: "{{ worker.service_name | kebab }}", When: "worker.port > 0"},
+		{Name: "project_name", Type: model.String},
+		{Name: "api.service_name", Type: model.String, Value: "{{ project_name }}-api"},
+		{Name: "api.port", Type: model.Int, Value: int64(8080)},
+		{Name: "api.module_path", Type: model.Computed, Expr: "{{ api.service_name | kebab }}", When: "api.port > 0"},
+	})
+	td.Cmp(t, got.Steps, []model.Step{
+		{Name: "go mod init", Module: "go", Action: model.InitAction, Scope: "api", CurrentWorkingDir: "{{ api.service_name }}",
+			Params: []string{"{{ api.module_path }}"}, When: `api.service_name != ""`},
+		{Name: "go mod init", Module: "go", Action: model.InitAction, Scope: "worker", CurrentWorkingDir: "{{ worker.service_name }}",
+			Params: []string{"{{ worker.module_path }}"}, When: `worker.service_name != ""`},
+	})
+	td.Cmp(t, got.FolderStruct, model.FolderStruct{
+		model.Folder{Name: "api", Filers: model.FolderStruct{
+			model.File{Name: "main.go", TempWrapper: &model.TempWrapper{
+				TemplateDef: model.TemplateDef{Engine: "gotmpl", Filepath: filepath.Join(dir, "main.tmpl")},
+				Namespace:   "api",
+			}},
+			model.File{Name: "{{ api.service_name }}.env"},
+			model.Folder{Name: "docker", When: "api.port", Filers: model.FolderStruct{model.File{Name: "compose.yaml"}}},
+		}},
+		model.Folder{Name: "worker", Filers: model.FolderStruct{
+			model.File{Name: "main.go", TempWrapper: &model.TempWrapper{
+				TemplateDef: model.TemplateDef{Engine: "gotmpl", Filepath: filepath.Join(dir, "main.tmpl")},
+				Namespace:   "worker",
+			}},
+			model.File{Name: "{{ worker.service_name }}.env"},
+			model.Folder{Name: "docker", When: "worker.port", Filers: model.FolderStruct{model.File{Name: "compose.yaml"}}},
+		}},
+	})
+}
+
+func TestParser_ParseIncludesTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+config:
+  includes:
+    - from: services/api/api.yaml
+      as: api
+folder_struct:
+  - README.md:
+      template: {engine: gotmpl, filepath: templates/README.tmpl}
+  - $api
+`,
+		"services/api/api.yaml": `version: 2
+config:
+  includes:
+    - from: ../../shared/db.yaml
+      as: db
+folder_struct:
+  - main.go:
+      template: {engine: gotmpl, filepath: templates/main.tmpl}
+  - store:
+    - $db
+`,
+		"shared/db.yaml": `version: 2
+folder_struct:
+  - schema.sql:
+      template: {engine: gotmpl, filepath: schema.tmpl}
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.FolderStruct, model.FolderStruct{
+		model.File{Name: "README.md", TempWrapper: &model.TempWrapper{
+			TemplateDef: model.TemplateDef{Engine: "gotmpl", Filepath: "templates/README.tmpl"},
+		}},
+		model.File{Name: "main.go", TempWrapper: &model.TempWrapper{
+			TemplateDef: model.TemplateDef{Engine: "gotmpl", Filepath: filepath.Join(dir, "services", "api", "templates", "main.tmpl")},
+		}},
+		model.Folder{Name: "store", Filers: model.FolderStruct{
+			model.File{Name: "schema.sql", TempWrapper: &model.TempWrapper{
+				TemplateDef: model.TemplateDef{Engine: "gotmpl", Filepath: filepath.Join(dir, "shared", "schema.tmpl")},
+			}},
+		}},
+	})
+}
+
+func TestParser_ParseIncludesErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		expectedErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nconfig:\n  includes:\n    - {from: a/a.yaml, as: a}\n",
+				"a/a.yaml":      "version: 2\nconfig:\n  includes:\n    - {from: ../workflow.yaml, as: root}\n",
+			},
+			expectedErr: "failed to resolve includes {dir}/workflow.yaml -> {dir}/a/a.yaml -> {dir}/workflow.yaml: include cycle",
+		},
+		{
+			name: "nested invalid workflow",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nconfig:\n  includes:\n    - {from: a.yaml, as: a}\n",
+				"a.yaml":        "version: 2\nconfig:\n  includes:\n    - {from: b.yaml, as: b}\n",
+				"b.yaml":        "version: 2\nsteps:\n  - {name: init, module: git, action: int}\n",
+			},
+			expectedErr: `failed to resolve includes {dir}/workflow.yaml -> {dir}/a.yaml -> {dir}/b.yaml: failed to check file ({dir}/b.yaml): {dir}/b.yaml:3:39: steps[0].action: invalid value "int", expected one of add, addOrigin, applyTemplate, commit, createFile, createFolder, createFolderStruct, init, installDevDeps, installGlobalDeps, installLocalDeps, push, writeFile (did you mean "init"?)`,
+		},
+		{
+			name: "alias without include",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nfolder_struct:\n  - $web\n",
+			},
+			expectedErr: "failed to resolve includes {dir}/workflow.yaml: folder $web has no matching include",
+		},
+		{
+			name: "alias used twice",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nconfig:\n  includes:\n    - {from: a.yaml, as: a}\n    - {from: a.yaml, as: a}\n",
+				"a.yaml":        "version: 2\n",
+			},
+			expectedErr: `failed to resolve includes {dir}/workflow.yaml: include alias "a" is used twice`,
+		},
+		{
+			name: "needs a step of another include",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nconfig:\n  includes:\n    - {from: a.yaml, as: a}\n    - {from: b.yaml, as: b}\n",
+				"a.yaml":        "version: 2\nsteps:\n  - {name: init, id: init, module: git, action: init}\n",
+				"b.yaml":        "version: 2\nsteps:\n  - {name: commit, needs: [init], module: git, action: commit}\n",
+			},
+			expectedErr: `failed to resolve includes {dir}/workflow.yaml -> {dir}/b.yaml: failed to check file ({dir}/b.yaml): step "commit" needs the unknown step init`,
+		},
+		{
+			name: "with an unknown var",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nconfig:\n  includes:\n    - {from: a.yaml, as: a, with: {port: 80}}\n",
+				"a.yaml":        "version: 2\nvars:\n  - {name: name, type: string}\n",
+			},
+			expectedErr: "failed to resolve includes {dir}/workflow.yaml: include a: with.port is not a var of a.yaml",
+		},
+		{
+			name: "with a var of the including workflow",
+			files: map[string]string{
+				"workflow.yaml": "version: 2\nconfig:\n  includes:\n    - {from: a.yaml, as: a, with: {name: api}}\nvars:\n  - {name: name, type: string}\n",
+				"a.yaml":        "version: 2\nvars:\n  - {name: name, type: string}\n",
+			},
+			expectedErr: "failed to resolve includes {dir}/workflow.yaml: include a: with.name is also a var of the including workflow, use a namespace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeWorkflows(t, dir, tt.files)
+
+			_, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+			td.CmpString(t, err, strings.ReplaceAll(tt.expectedErr, "{dir}", dir))
+		})
+	}
+}
+
+func TestParser_ParseRemoteIncludes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/workflows/go.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("version: 2\nconfig:\n  includes:\n    - {from: parts/lint.yaml, as: lint}\n"))
+	})
+	mux.HandleFunc("/workflows/parts/lint.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("version: 2\nsteps:\n  - {name: lint, module: golangci, action: init}\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := parser.NewParser()
+	defer p.Cleanup()
+	got, err := p.Parse(server.URL + "/workflows/go.yaml")
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Steps, []model.Step{{Name: "lint", Module: "golangci", Action: model.InitAction}})
+}
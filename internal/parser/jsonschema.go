@@ -0,0 +1,626 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	cueparser "cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+const (
+	jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	// rootDefinition is the definition a workflow file is validated against.
+	rootDefinition = "#Workflow"
+)
+
+// A schema is a JSON Schema object.
+type schema = map[string]any
+
+// Schema returns the cue schema workflow files are checked against.
+func Schema() string {
+	return schemaFile
+}
+
+// JSONSchema exports the workflow schema as a JSON Schema (draft 2020-12), for editors to complete
+// and validate workflow files. Constraints between fields (e.g. max >= min) cannot be expressed
+// in JSON Schema, they are left out: [Parser.Check] remains the reference.
+func JSONSchema() ([]byte, error) {
+	file, err := cueparser.ParseFile("workflow.cue", schemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	g := jsonSchemaGenerator{hidden: make(map[string]ast.Expr)}
+	var definitions []*ast.Field
+	for _, decl := range file.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, _ := ast.LabelName(field.Label)
+		switch {
+		case strings.HasPrefix(name, "#"):
+			definitions = append(definitions, field)
+		case strings.HasPrefix(name, "_"):
+			g.hidden[name] = field.Value
+		}
+	}
+
+	defs := make(schema, len(definitions))
+	for _, field := range definitions {
+		name, _, _ := ast.LabelName(field.Label)
+		def, err := g.expr(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", name, err)
+		}
+		defs[strings.TrimPrefix(name, "#")] = def
+	}
+
+	return json.MarshalIndent(schema{
+		"$schema": jsonSchemaDraft,
+		"title":   "boot workflow",
+		"$ref":    "#/$defs/" + strings.TrimPrefix(rootDefinition, "#"),
+		"$defs":   defs,
+	}, "", "  ")
+}
+
+// jsonSchemaGenerator translates the cue syntax of the workflow schema into JSON Schema.
+type jsonSchemaGenerator struct {
+	// hidden are the hidden fields of the schema (_name), they are inlined where they are used.
+	hidden map[string]ast.Expr
+}
+
+// expr translates a cue expression.
+func (g jsonSchemaGenerator) expr(expr ast.Expr) (schema, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return g.expr(e.X)
+	case *ast.Ident:
+		return g.ident(e)
+	case *ast.BasicLit:
+		value, err := g.literal(e)
+		if err != nil {
+			return nil, err
+		}
+		return schema{"const": value}, nil
+	case *ast.StructLit:
+		return g.structLit(e)
+	case *ast.ListLit:
+		return g.listLit(e)
+	case *ast.UnaryExpr:
+		return g.unary(e)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.OR:
+			return g.disjunction(e)
+		case token.AND:
+			return g.conjunction(e)
+		}
+	}
+	return nil, fmt.Errorf("unsupported expression %s", formatNode(expr))
+}
+
+// ident translates the cue types and the references to other definitions.
+func (g jsonSchemaGenerator) ident(e *ast.Ident) (schema, error) {
+	switch {
+	case strings.HasPrefix(e.Name, "#"):
+		return schema{"$ref": "#/$defs/" + strings.TrimPrefix(e.Name, "#")}, nil
+	case strings.HasPrefix(e.Name, "_") && e.Name != "_":
+		hidden, ok := g.hidden[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown reference %s", e.Name)
+		}
+		return g.expr(hidden)
+	}
+
+	switch e.Name {
+	case "_":
+		return schema{}, nil
+	case "string", "bytes":
+		return schema{"type": "string"}, nil
+	case "bool":
+		return schema{"type": "boolean"}, nil
+	case "int":
+		return schema{"type": "integer"}, nil
+	case "number", "float":
+		return schema{"type": "number"}, nil
+	case "null":
+		return schema{"type": "null"}, nil
+	}
+	return nil, fmt.Errorf("unsupported reference to field %s", e.Name)
+}
+
+// literal returns the value of a literal, or of the hidden field it refers to.
+func (g jsonSchemaGenerator) literal(expr ast.Expr) (any, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if hidden, ok := g.hidden[e.Name]; ok {
+			return g.literal(hidden)
+		}
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return literal.Unquote(e.Value)
+		case token.INT, token.FLOAT:
+			return json.Number(e.Value), nil
+		case token.TRUE, token.FALSE:
+			return e.Kind == token.TRUE, nil
+		case token.NULL:
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a literal", formatNode(expr))
+}
+
+// pattern returns the regular expression of a =~ or !~ constraint.
+func (g jsonSchemaGenerator) pattern(expr ast.Expr) (string, error) {
+	value, err := g.literal(expr)
+	if err != nil {
+		return "", err
+	}
+	pattern, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s is not a regular expression", formatNode(expr))
+	}
+	return pattern, nil
+}
+
+// unary translates bounds, regular expression constraints and defaults.
+func (g jsonSchemaGenerator) unary(e *ast.UnaryExpr) (schema, error) {
+	if e.Op == token.MUL {
+		res, err := g.expr(e.X)
+		if err != nil {
+			return nil, err
+		}
+		value, err := g.literal(e.X)
+		if err != nil {
+			return nil, err
+		}
+		res["default"] = value
+		return res, nil
+	}
+
+	if e.Op == token.MAT || e.Op == token.NMAT {
+		pattern, err := g.pattern(e.X)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == token.NMAT {
+			return schema{"type": "string", "not": schema{"pattern": pattern}}, nil
+		}
+		return schema{"type": "string", "pattern": pattern}, nil
+	}
+
+	keywords := map[token.Token]string{
+		token.GEQ: "minimum",
+		token.GTR: "exclusiveMinimum",
+		token.LEQ: "maximum",
+		token.LSS: "exclusiveMaximum",
+	}
+	keyword, ok := keywords[e.Op]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operator %s", e.Op)
+	}
+	bound, err := g.literal(e.X)
+	if err != nil {
+		return nil, err
+	}
+	return schema{keyword: bound}, nil
+}
+
+// disjunction translates a | b. Literals already accepted by a type of the disjunction are dropped,
+// disjunctions of strings become enums.
+func (g jsonSchemaGenerator) disjunction(e *ast.BinaryExpr) (schema, error) {
+	var (
+		members []schema
+		def     any
+		hasDef  bool
+	)
+	for _, expr := range flattenBinary(e, token.OR) {
+		member, err := g.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := member["default"]; ok {
+			def, hasDef = value, true
+			delete(member, "default")
+		}
+		members = append(members, member)
+	}
+
+	var (
+		types  = make(map[string]bool)
+		consts []any
+		others []schema
+	)
+	for _, member := range members {
+		if t, ok := member["type"].(string); ok && len(member) == 1 {
+			types[t] = true
+		}
+	}
+	for _, member := range members {
+		value, ok := member["const"]
+		if !ok || len(member) > 1 {
+			if !slices.ContainsFunc(others, func(other schema) bool { return reflect.DeepEqual(other, member) }) {
+				others = append(others, member)
+			}
+			continue
+		}
+		if !types[jsonType(value)] {
+			consts = append(consts, value)
+		}
+	}
+
+	var res schema
+	switch {
+	case len(others) == 0 && allStrings(consts):
+		res = schema{"type": "string", "enum": consts}
+	case len(consts) == 0 && len(others) == 1:
+		res = others[0]
+	default:
+		anyOf := others
+		if len(consts) > 0 {
+			anyOf = append(anyOf, schema{"enum": consts})
+		}
+		res = schema{"anyOf": anyOf}
+	}
+	if hasDef {
+		res["default"] = def
+	}
+	return res, nil
+}
+
+// conjunction translates a & b, merged in a single schema when they use different keywords.
+func (g jsonSchemaGenerator) conjunction(e *ast.BinaryExpr) (schema, error) {
+	var (
+		members []any
+		merged  = schema{}
+		merge   = true
+	)
+	for _, expr := range flattenBinary(e, token.AND) {
+		member, err := g.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+		for key, value := range member {
+			if existing, ok := merged[key]; ok && !reflect.DeepEqual(existing, value) {
+				merge = false
+			}
+			merged[key] = value
+		}
+	}
+	if merge {
+		return merged, nil
+	}
+	return schema{"allOf": members}, nil
+}
+
+// listLit translates [...T] and [A, B, ...T]. Leading elements of the type of the
+// remaining ones are a minimum number of items.
+func (g jsonSchemaGenerator) listLit(e *ast.ListLit) (schema, error) {
+	res := schema{"type": "array"}
+	var prefix []schema
+	for _, elt := range e.Elts {
+		ellipsis, ok := elt.(*ast.Ellipsis)
+		if !ok {
+			item, err := g.expr(elt)
+			if err != nil {
+				return nil, err
+			}
+			prefix = append(prefix, item)
+			continue
+		}
+		if ellipsis.Type == nil {
+			continue
+		}
+		items, err := g.expr(ellipsis.Type)
+		if err != nil {
+			return nil, err
+		}
+		res["items"] = items
+	}
+
+	if len(prefix) == 0 {
+		return res, nil
+	}
+	if items, ok := res["items"]; ok && allSchemasEqual(prefix, items) {
+		res["minItems"] = len(prefix)
+		return res, nil
+	}
+	res["prefixItems"] = prefix
+	res["minItems"] = len(prefix)
+	if !hasEllipsis(e) {
+		res["maxItems"] = len(prefix)
+	}
+	return res, nil
+}
+
+// structLit translates a struct, closed as every struct of a definition. Conditional fields (if blocks)
+// become if/then schemas, conditions that cannot be expressed in JSON Schema are left out.
+func (g jsonSchemaGenerator) structLit(e *ast.StructLit) (schema, error) {
+	var (
+		closed     = true
+		res        = schema{"type": "object"}
+		properties = schema{}
+		required   []string
+		allOf      []any
+		embeds     []any
+	)
+	for _, elt := range e.Elts {
+		switch decl := elt.(type) {
+		case *ast.Field:
+			if list, ok := decl.Label.(*ast.ListLit); ok && len(list.Elts) == 1 {
+				// a pattern constraint on the names of the fields: [pattern]: value
+				names, err := g.expr(list.Elts[0])
+				if err != nil {
+					return nil, err
+				}
+				value, err := g.expr(decl.Value)
+				if err != nil {
+					return nil, err
+				}
+				res["propertyNames"], res["additionalProperties"] = names, value
+				continue
+			}
+
+			name, _, err := ast.LabelName(decl.Label)
+			if err != nil {
+				return nil, err
+			}
+			value, err := g.expr(decl.Value)
+			if err != nil {
+				return nil, err
+			}
+			properties[name] = value
+			_, hasDefault := value["default"]
+			if decl.Constraint == token.NOT || (decl.Constraint == token.ILLEGAL && !hasDefault) {
+				required = append(required, name)
+			}
+		case *ast.EmbedDecl:
+			embed, err := g.expr(decl.Expr)
+			if err != nil {
+				return nil, err
+			}
+			embeds = append(embeds, embed)
+		case *ast.Comprehension:
+			conditional, ok := g.comprehension(decl)
+			if !ok {
+				continue
+			}
+			allOf = append(allOf, conditional)
+			// fields set in the conditional are allowed, their constraints are in the then schema
+			if then, ok := conditional["then"].(schema); ok {
+				thenProperties, _ := then["properties"].(schema)
+				for name := range thenProperties {
+					if _, ok := properties[name]; !ok {
+						properties[name] = schema{}
+					}
+				}
+				if _, ok := then["$ref"]; ok {
+					// the embedded definition is closed on its own
+					closed = false
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported declaration %s", formatNode(elt))
+		}
+	}
+
+	if len(embeds) > 0 {
+		allOf = append(embeds, allOf...)
+		closed = false
+	}
+	if len(properties) > 0 {
+		res["properties"] = properties
+	}
+	if len(required) > 0 {
+		res["required"] = required
+	}
+	if len(allOf) > 0 {
+		res["allOf"] = allOf
+	}
+	if _, ok := res["additionalProperties"]; !ok && closed {
+		res["additionalProperties"] = false
+	}
+	return res, nil
+}
+
+// comprehension translates `if condition { fields }` into an if/then schema.
+// It returns false when either the condition or the fields cannot be expressed in JSON Schema.
+func (g jsonSchemaGenerator) comprehension(c *ast.Comprehension) (schema, bool) {
+	if len(c.Clauses) != 1 {
+		return nil, false
+	}
+	clause, ok := c.Clauses[0].(*ast.IfClause)
+	if !ok {
+		return nil, false
+	}
+	condition, ok := g.condition(clause.Condition)
+	if !ok {
+		return nil, false
+	}
+	body, ok := c.Value.(*ast.StructLit)
+	if !ok {
+		return nil, false
+	}
+
+	then := schema{}
+	properties := schema{}
+	var required []string
+	for _, elt := range body.Elts {
+		switch decl := elt.(type) {
+		case *ast.Field:
+			name, _, err := ast.LabelName(decl.Label)
+			if err != nil {
+				return nil, false
+			}
+			value, err := g.expr(decl.Value)
+			if err != nil {
+				// a constraint on another field, e.g. max: >=min
+				continue
+			}
+			properties[name] = value
+			if decl.Constraint == token.NOT {
+				required = append(required, name)
+			}
+		case *ast.EmbedDecl:
+			embed, err := g.expr(decl.Expr)
+			if err != nil {
+				return nil, false
+			}
+			for key, value := range embed {
+				then[key] = value
+			}
+		}
+	}
+	if len(properties) > 0 {
+		then["properties"] = properties
+	}
+	if len(required) > 0 {
+		then["required"] = required
+	}
+	if len(then) == 0 {
+		return nil, false
+	}
+	return schema{"if": condition, "then": then}, true
+}
+
+// condition translates the condition of an if block. Only comparisons of fields
+// with literals, field presence and their combinations are supported.
+func (g jsonSchemaGenerator) condition(expr ast.Expr) (schema, bool) {
+	e, ok := expr.(*ast.BinaryExpr)
+	if !ok {
+		return nil, false
+	}
+
+	switch e.Op {
+	case token.LOR, token.LAND:
+		var members []any
+		for _, member := range flattenBinary(e, e.Op) {
+			condition, ok := g.condition(member)
+			if !ok {
+				return nil, false
+			}
+			members = append(members, condition)
+		}
+		if e.Op == token.LOR {
+			return schema{"anyOf": members}, true
+		}
+		return schema{"allOf": members}, true
+	}
+
+	field, ok := e.X.(*ast.Ident)
+	if !ok || strings.HasPrefix(field.Name, "#") {
+		return nil, false
+	}
+	present := schema{"required": []string{field.Name}}
+	if _, ok := e.Y.(*ast.BottomLit); ok {
+		switch e.Op {
+		case token.NEQ:
+			return present, true
+		case token.EQL:
+			return schema{"not": present}, true
+		}
+		return nil, false
+	}
+
+	var constraint schema
+	switch e.Op {
+	case token.EQL, token.NEQ:
+		value, err := g.literal(e.Y)
+		if err != nil {
+			return nil, false
+		}
+		constraint = schema{"const": value}
+		if e.Op == token.NEQ {
+			constraint = schema{"not": constraint}
+		}
+	case token.MAT, token.NMAT:
+		pattern, err := g.pattern(e.Y)
+		if err != nil {
+			return nil, false
+		}
+		constraint = schema{"pattern": pattern}
+		if e.Op == token.NMAT {
+			constraint = schema{"not": constraint}
+		}
+	default:
+		return nil, false
+	}
+	present["properties"] = schema{field.Name: constraint}
+	return present, true
+}
+
+// flattenBinary returns the operands of a chain of op: a | b | c.
+func flattenBinary(expr ast.Expr, op token.Token) []ast.Expr {
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		expr = paren.X
+	}
+	e, ok := expr.(*ast.BinaryExpr)
+	if !ok || e.Op != op {
+		return []ast.Expr{expr}
+	}
+	return append(flattenBinary(e.X, op), flattenBinary(e.Y, op)...)
+}
+
+func hasEllipsis(e *ast.ListLit) bool {
+	for _, elt := range e.Elts {
+		if _, ok := elt.(*ast.Ellipsis); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a literal.
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case nil:
+		return "null"
+	}
+	return ""
+}
+
+func allStrings(values []any) bool {
+	for _, value := range values {
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+func allSchemasEqual(schemas []schema, other any) bool {
+	for _, s := range schemas {
+		if !reflect.DeepEqual(s, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatNode returns the cue source of a node, for error messages.
+func formatNode(node ast.Node) string {
+	src, err := format.Node(node)
+	if err != nil {
+		return fmt.Sprintf("%T", node)
+	}
+	return string(src)
+}
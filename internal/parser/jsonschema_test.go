@@ -0,0 +1,89 @@
+package parser_test
+
+import (
+	"os"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding/jsonschema"
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+const jsonSchemaFile = "workflow.schema.json"
+
+func TestJSONSchema(t *testing.T) {
+	got, err := parser.JSONSchema()
+	td.Require(t).CmpNoError(err)
+
+	published, err := os.ReadFile(jsonSchemaFile)
+	td.Require(t).CmpNoError(err)
+	td.Require(t).Cmp(string(got)+"\n", string(published),
+		"%s is out of date, run: boot schema --format jsonschema > internal/parser/%s", jsonSchemaFile, jsonSchemaFile)
+
+	// the JSON Schema accepts and rejects the same workflows as workflow.cue
+	ctx := cuecontext.New()
+	schemaFile, err := jsonschema.Extract(ctx.CompileBytes(got), &jsonschema.Config{})
+	td.Require(t).CmpNoError(err)
+	schema := ctx.BuildFile(schemaFile)
+	td.Require(t).CmpNoError(schema.Err())
+
+	mock, err := helper.CueUnmarshalFile(ctx, "../mocks/workflow.yaml")
+	td.Require(t).CmpNoError(err)
+
+	testCases := []struct {
+		name     string
+		workflow cue.Value
+		valid    bool
+	}{
+		{name: "mock", workflow: *mock, valid: true},
+		{name: "empty", workflow: ctx.CompileString(`{version: 2}`), valid: true},
+		{name: "include", workflow: ctx.CompileString(`{version: 2, config: {includes: [{from: "base.yaml", as: "base"}]}}`), valid: true},
+		{name: "include without alias", workflow: ctx.CompileString(`{version: 2, config: {includes: [{from: "base.yaml"}]}}`)},
+		{name: "v1 include", workflow: ctx.CompileString(`{version: 2, config: {from: "base.yaml"}}`)},
+		{name: "unknown field", workflow: ctx.CompileString(`{version: 2, output: "dist"}`)},
+		{name: "unknown var type", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "strin"}]}`)},
+		{name: "missing var name", workflow: ctx.CompileString(`{version: 2, vars: [{type: "string"}]}`)},
+		{name: "select without options", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "select"}]}`)},
+		{name: "select", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "select", options: [{value: "b"}]}]}`), valid: true},
+		{name: "computed without expr", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "computed"}]}`)},
+		{name: "int default", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "int", default: 2}]}`), valid: true},
+		{name: "wrong int default", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "int", default: "2"}]}`)},
+		{name: "negative min_length", workflow: ctx.CompileString(`{version: 2, vars: [{name: "a", type: "string", min_length: -1}]}`)},
+		{name: "license step", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "license"}]}`), valid: true},
+		{name: "license step with action", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "license", action: "init"}]}`)},
+		{name: "module step without action", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "git"}]}`)},
+		{name: "unknown action", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "git", action: "int"}]}`)},
+		{name: "retried step", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "npm", action: "installLocalDeps", retries: 2, timeout: "1m30s", continue_on_error: true}]}`), valid: true},
+		{name: "negative retries", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "npm", action: "installLocalDeps", retries: -1}]}`)},
+		{name: "timeout without unit", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "npm", action: "installLocalDeps", timeout: "90"}]}`)},
+		{name: "license step with timeout", workflow: ctx.CompileString(`{version: 2, steps: [{name: "a", module: "license", timeout: "1m"}]}`)},
+		{name: "hooks", workflow: ctx.CompileString(`{version: 2, hooks: {pre_gen: [{name: "a", module: "node", action: "init"}], on_failure: [{use: "notify"}]}}`), valid: true},
+		{name: "unknown hook", workflow: ctx.CompileString(`{version: 2, hooks: {post_install: [{name: "a", module: "node", action: "init"}]}}`)},
+		{name: "use step", workflow: ctx.CompileString(`{version: 2, steps: [{use: "commit", id: "commit", with: {message: "init"}, when: "git"}]}`), valid: true},
+		{name: "use step with module", workflow: ctx.CompileString(`{version: 2, steps: [{use: "commit", module: "git"}]}`)},
+		{name: "step group", workflow: ctx.CompileString(`{version: 2, step_groups: {commit: {params: [{name: "message", default: "init"}], steps: [{name: "a", module: "git", action: "commit"}]}}}`), valid: true},
+		{name: "empty step group", workflow: ctx.CompileString(`{version: 2, step_groups: {commit: {steps: []}}}`)},
+		{name: "invalid param name", workflow: ctx.CompileString(`{version: 2, step_groups: {commit: {params: [{name: "a-b"}], steps: [{use: "b"}]}}}`)},
+		{name: "template", workflow: ctx.CompileString(`{version: 2, folder_struct: [{"main.go": {template: {engine: "gotmpl", filepath: "main.tmpl"}}}]}`), valid: true},
+		{name: "template without engine", workflow: ctx.CompileString(`{version: 2, folder_struct: [{"main.go": {template: {filepath: "main.tmpl"}}}]}`)},
+		{name: "template with expression", workflow: ctx.CompileString(`{version: 2, folder_struct: [{"{{ project_name }}": [{"{{ project_name | snake }}.go": {template: {engine: "gotmpl", filepath: "main.tmpl"}}}]}]}`), valid: true},
+		{name: "folder", workflow: ctx.CompileString(`{version: 2, folder_struct: [{cmd: ["main.go", {docker: {when: "docker"}}]}]}`), valid: true},
+		{name: "folder without when", workflow: ctx.CompileString(`{version: 2, folder_struct: [{docker: {children: []}}]}`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cueErr := parser.NewParser().Check(ctx, tc.workflow)
+			jsonErr := schema.Unify(tc.workflow).Validate(cue.Concrete(true))
+			if tc.valid {
+				td.CmpNoError(t, cueErr, "workflow.cue")
+				td.CmpNoError(t, jsonErr, jsonSchemaFile)
+				return
+			}
+			td.CmpError(t, cueErr, "workflow.cue")
+			td.CmpError(t, jsonErr, jsonSchemaFile)
+		})
+	}
+}
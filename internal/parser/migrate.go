@@ -0,0 +1,351 @@
+package parser
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/bootengine/boot/internal/helper"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the workflow format described by workflow.cue.
+// Files without a version field are version 1 files.
+const CurrentVersion = 2
+
+// schemas holds the schemas of the previous versions of the workflow format.
+//
+//go:embed schemas/*.cue
+var schemas embed.FS
+
+// A migration upgrades a workflow document to the next version of the format,
+// it returns a description of every change made.
+type migration func(doc *yaml.Node) ([]string, error)
+
+// migrations are the upgrades from each previous version.
+var migrations = map[int]migration{
+	1: migrateV1,
+}
+
+// A Migration is the upgrade of a workflow file to the current version.
+type Migration struct {
+	Filename string
+	From, To int
+	Changes  []string
+	// Content is the upgraded file, nil when the file type cannot be rewritten (toml and cue):
+	// the changes have to be made by hand.
+	Content []byte
+}
+
+// WorkflowVersion returns the version of the format a workflow is written in.
+func WorkflowVersion(value cue.Value) (int, error) {
+	v := value.LookupPath(cue.ParsePath("version"))
+	if !v.Exists() {
+		return 1, nil
+	}
+	version, err := v.Int64()
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 || version > CurrentVersion {
+		return 0, fmt.Errorf("unsupported version %d, this boot supports versions 1 to %d", version, CurrentVersion)
+	}
+	return int(version), nil
+}
+
+// versionSchema returns the schema of a version of the workflow format.
+func versionSchema(version int) (string, error) {
+	if version == CurrentVersion {
+		return schemaFile, nil
+	}
+	content, err := schemas.ReadFile(fmt.Sprintf("schemas/v%d.cue", version))
+	if err != nil {
+		return "", fmt.Errorf("no schema for version %d: %w", version, err)
+	}
+	return string(content), nil
+}
+
+// MigrateFile upgrades a workflow file to the current version. Yaml files keep their comments,
+// the file itself is left untouched.
+func MigrateFile(filename string) (Migration, error) {
+	m := Migration{Filename: filename, To: CurrentVersion}
+	fileType := helper.SupportedFileType(strings.TrimPrefix(filepath.Ext(filename), "."))
+
+	var (
+		doc yaml.Node
+		err error
+	)
+	switch fileType {
+	case helper.YAML, helper.YML, helper.JSON:
+		var content []byte
+		if content, err = os.ReadFile(filename); err != nil {
+			return m, err
+		}
+		err = yaml.Unmarshal(content, &doc)
+	default:
+		// toml and cue files are migrated from their values, they cannot be written back as is
+		var content []byte
+		if content, err = workflowJSON(filename); err != nil {
+			return m, err
+		}
+		err = yaml.Unmarshal(content, &doc)
+	}
+	if err != nil {
+		return m, err
+	}
+
+	if m.From, err = documentVersion(&doc); err != nil {
+		return m, err
+	}
+	if m.Changes, err = migrate(&doc, m.From); err != nil {
+		return m, err
+	}
+
+	switch fileType {
+	case helper.YAML, helper.YML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err = enc.Encode(&doc); err != nil {
+			return m, err
+		}
+		m.Content = buf.Bytes()
+	case helper.JSON:
+		if m.Content, err = nodeJSON(&doc, "  "); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// migrateValue upgrades a workflow value of an older version to the current version.
+func migrateValue(ctx *cue.Context, value cue.Value, version int) (cue.Value, error) {
+	content, err := value.MarshalJSON()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return cue.Value{}, err
+	}
+	if _, err = migrate(&doc, version); err != nil {
+		return cue.Value{}, err
+	}
+	if content, err = nodeJSON(&doc, ""); err != nil {
+		return cue.Value{}, err
+	}
+	migrated := ctx.CompileBytes(content)
+	return migrated, migrated.Err()
+}
+
+// migrate applies the migrations from version to the current version.
+func migrate(doc *yaml.Node, version int) ([]string, error) {
+	root := documentRoot(doc)
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("a workflow must be an object")
+	}
+
+	var changes []string
+	for ; version < CurrentVersion; version++ {
+		m, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from version %d", version)
+		}
+		migrationChanges, err := m(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate from version %d: %w", version, err)
+		}
+		changes = append(changes, migrationChanges...)
+	}
+
+	if len(changes) > 0 || mappingValue(root, "version") == nil {
+		setVersion(root, version)
+		changes = append(changes, fmt.Sprintf("version set to %d", version))
+	}
+	return changes, nil
+}
+
+// migrateV1 moves config.from to config.includes, included workflows are named after their file.
+func migrateV1(root *yaml.Node) ([]string, error) {
+	config := mappingValue(root, "config")
+	if config == nil || config.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i < len(config.Content); i += 2 {
+		key, from := config.Content[i], config.Content[i+1]
+		if key.Value != "from" {
+			continue
+		}
+		alias := includeAlias(from.Value)
+		key.Value = "includes"
+		config.Content[i+1] = &yaml.Node{
+			Kind:  yaml.SequenceNode,
+			Style: from.Style & yaml.FlowStyle,
+			Content: []*yaml.Node{{
+				Kind:  yaml.MappingNode,
+				Style: from.Style & yaml.FlowStyle,
+				Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "from"}, from,
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "as"}, {Kind: yaml.ScalarNode, Tag: "!!str", Value: alias},
+				},
+			}},
+		}
+		return []string{fmt.Sprintf("config.from moved to config.includes[0], included as %q", alias)}, nil
+	}
+	return nil, nil
+}
+
+var invalidAliasChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// includeAlias names an included workflow after its file: git+https://host/repo//go/cli.yaml@v1 is cli.
+func includeAlias(from string) string {
+	from, _, _ = strings.Cut(path.Base(filepath.ToSlash(from)), "@")
+	from, _, _ = strings.Cut(from, ".")
+	alias := strings.Trim(invalidAliasChars.ReplaceAllString(from, "_"), "_")
+	if alias == "" {
+		return "include"
+	}
+	return alias
+}
+
+// documentVersion returns the version of a workflow document.
+func documentVersion(doc *yaml.Node) (int, error) {
+	root := documentRoot(doc)
+	v := mappingValue(root, "version")
+	if v == nil {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(v.Value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q", v.Value)
+	}
+	if version < 1 || version > CurrentVersion {
+		return 0, fmt.Errorf("unsupported version %d, this boot supports versions 1 to %d", version, CurrentVersion)
+	}
+	return version, nil
+}
+
+// setVersion sets the version field, first field of the workflow.
+func setVersion(root *yaml.Node, version int) {
+	value := strconv.Itoa(version)
+	if v := mappingValue(root, "version"); v != nil {
+		v.Value = value
+		return
+	}
+	root.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+		{Kind: yaml.ScalarNode, Tag: "!!int", Value: value},
+	}, root.Content...)
+	if len(root.Content) > 2 {
+		// the head comment of the file stays on top
+		root.Content[0].HeadComment, root.Content[2].HeadComment = root.Content[2].HeadComment, ""
+	}
+}
+
+// documentRoot returns the top level node of a yaml document.
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// mappingValue returns the value of key in a mapping node, nil when it is not set.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// workflowJSON reads a workflow file of any supported type as json.
+func workflowJSON(filename string) ([]byte, error) {
+	value, err := helper.CueUnmarshalFile(cuecontext.New(), filename)
+	if err != nil {
+		return nil, err
+	}
+	return value.MarshalJSON()
+}
+
+// nodeJSON writes a yaml document as json, keeping the order of the fields.
+func nodeJSON(node *yaml.Node, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeNodeJSON(&buf, node); err != nil {
+		return nil, err
+	}
+	if indent == "" {
+		return buf.Bytes(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", indent); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func writeNodeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeNodeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeNodeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err = writeNodeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeNodeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		scalar, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(scalar)
+	}
+	return nil
+}
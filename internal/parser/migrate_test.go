@@ -0,0 +1,127 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestCurrentVersion(t *testing.T) {
+	version, err := cuecontext.New().CompileString(parser.Schema()).LookupPath(cue.ParsePath("#Version")).Int64()
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, version, int64(parser.CurrentVersion), "CurrentVersion must match #Version of workflow.cue")
+}
+
+func TestMigrateFile(t *testing.T) {
+	const change = `config.from moved to config.includes[0], included as "go-base"`
+
+	t.Run("yaml", func(t *testing.T) {
+		got, err := parser.MigrateFile("../mocks/workflow_v1.yaml")
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, got, parser.Migration{
+			Filename: "../mocks/workflow_v1.yaml",
+			From:     1,
+			To:       parser.CurrentVersion,
+			Changes:  []string{change, "version set to 2"},
+			Content: []byte(`# a workflow written before includes were named
+version: 2
+config:
+  create_root: true # the project gets its own folder
+  # steps and vars shared by every go project
+  includes:
+    - from: includes/go-base.yaml
+      as: go-base
+vars:
+  - name: project_name
+    type: string
+    required: true
+steps:
+  - name: git init
+    module: git
+    action: init
+`),
+		})
+	})
+
+	t.Run("json", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "workflow.json")
+		td.Require(t).CmpNoError(os.WriteFile(filename, []byte(`{"config": {"from": "go-base.json"}, "steps": []}`), 0644))
+
+		got, err := parser.MigrateFile(filename)
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, got.Changes, []string{change, "version set to 2"})
+		td.Cmp(t, string(got.Content), `{
+  "version": 2,
+  "config": {
+    "includes": [
+      {
+        "from": "go-base.json",
+        "as": "go-base"
+      }
+    ]
+  },
+  "steps": []
+}
+`)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "workflow.toml")
+		td.Require(t).CmpNoError(os.WriteFile(filename, []byte("[config]\nfrom = \"go-base.toml\"\n"), 0644))
+
+		got, err := parser.MigrateFile(filename)
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, got.Changes, []string{change, "version set to 2"})
+		td.CmpNil(t, got.Content)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		got, err := parser.MigrateFile("../mocks/workflow.yaml")
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, got.From, parser.CurrentVersion)
+		td.CmpEmpty(t, got.Changes)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "workflow.yaml")
+		td.Require(t).CmpNoError(os.WriteFile(filename, []byte("version: 99\n"), 0644))
+
+		_, err := parser.MigrateFile(filename)
+		td.CmpString(t, err, "unsupported version 99, this boot supports versions 1 to 2")
+	})
+}
+
+func TestParser_ParseOutdated(t *testing.T) {
+	got, err := parser.NewParser().Parse("../mocks/workflow_v1.yaml")
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Version, parser.CurrentVersion)
+	td.Cmp(t, got.Config, model.Config{
+		CreateRoot: true,
+		Includes:   []model.Include{{From: "includes/go-base.yaml", As: "go-base"}},
+	})
+	td.Cmp(t, got.Vars, model.Vars{
+		{Name: "go_version", Type: model.String, Default: "1.23"},
+		{Name: "project_name", Type: model.String, Required: true},
+	})
+}
+
+func TestParser_CheckV1(t *testing.T) {
+	ctx := cuecontext.New()
+	// select vars came with version 2, a file without a version cannot use them
+	value := ctx.CompileString(`vars: [{name: "frontend", type: "select", required: true, options: [{value: "react"}]}]`)
+
+	err := parser.NewParser().Check(ctx, value)
+	td.Cmp(t, err, td.Smuggle(func(errs parser.CheckErrors) []string {
+		paths := make([]string, len(errs))
+		for i, e := range errs {
+			paths[i] = e.Path + ": " + e.Message
+		}
+		return paths
+	}, td.SuperBagOf(`vars[0].type: invalid value "select"`, "vars[0].options: field not allowed")))
+}
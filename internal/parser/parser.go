@@ -1,25 +1,46 @@
 package parser
 
 import (
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
-	"regexp"
+	"os"
 
-	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	"github.com/bootengine/boot/internal/helper"
 	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/log"
 )
 
 //go:embed workflow.cue
 var schemaFile string
 
-type Parser struct{}
+type Parser struct {
+	// fetched holds the directories where remote workflows have been downloaded.
+	fetched []string
+	// cache keeps remote workflows across runs, when set.
+	cache *Cache
+	// modules are the names of the installed modules, suggested for misspelled module names.
+	modules []string
+}
 
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+// WithModules makes the parser suggest the installed modules named modules for misspelled module names.
+func (p *Parser) WithModules(modules []string) *Parser {
+	p.modules = modules
+	return p
+}
+
+// WithCache makes the parser keep remote workflows in c, instead of temporary directories.
+func (p *Parser) WithCache(c *Cache) *Parser {
+	p.cache = c
+	return p
+}
+
 type ParserError struct {
 	action, filename string
 	err              error
@@ -29,26 +50,39 @@ func (p ParserError) Error() string {
 	return fmt.Sprintf("failed to %s file (%s): %s", p.action, p.filename, p.err)
 }
 
-func (p Parser) Check(ctx *cue.Context, value cue.Value) error {
-	schema := ctx.CompileString(schemaFile).LookupPath(cue.ParsePath("#Workflow"))
-	unified := schema.Unify(value)
-	return unified.Validate()
+func (p ParserError) Unwrap() error {
+	return p.err
+}
+
+// Parse reads, checks and decodes a workflow file, then resolves its includes. Remote workflows are downloaded
+// first, they are kept on disk until [Parser.Cleanup] is called, or in the [Cache].
+func (p *Parser) Parse(filename string) (*model.Workflow, error) {
+	return p.parse(filename, nil)
 }
 
-func (p Parser) Parse(filename string) (*model.Workflow, error) {
+// parseFile reads, checks and decodes a single workflow file, it returns the path of its local copy
+// along with the workflow.
+func (p *Parser) parseFile(filename string) (*model.Workflow, string, error) {
 	var (
 		workflow model.Workflow
 		err      error
 		ctx      = cuecontext.New()
+		local    = filename
+		remote   RemoteSource
 	)
 
-	if filenameIsURL(filename) {
-		// clone in temp dir or query the file
+	if FilenameIsURL(filename) {
+		if remote, err = ParseRemoteSource(filename); err != nil {
+			return nil, "", ParserError{action: "fetch", err: err, filename: filename}
+		}
+		if local, err = p.fetch(remote); err != nil {
+			return nil, "", ParserError{action: "fetch", err: err, filename: filename}
+		}
 	}
 
-	cueValue, err := helper.CueUnmarshalFile(ctx, filename)
+	cueValue, err := helper.CueUnmarshalFile(ctx, local)
 	if err != nil {
-		return nil, ParserError{
+		return nil, "", ParserError{
 			action:   "read",
 			err:      err,
 			filename: filename,
@@ -56,7 +90,7 @@ func (p Parser) Parse(filename string) (*model.Workflow, error) {
 	}
 
 	if err = p.Check(ctx, *cueValue); err != nil {
-		return nil, ParserError{
+		return nil, "", ParserError{
 			action:   "check",
 			err:      err,
 			filename: filename,
@@ -64,18 +98,57 @@ func (p Parser) Parse(filename string) (*model.Workflow, error) {
 
 	}
 
+	version, err := WorkflowVersion(*cueValue)
+	if err != nil {
+		return nil, "", ParserError{action: "check", err: err, filename: filename}
+	}
+	if version < CurrentVersion {
+		log.Warn("outdated workflow, run boot migrate to upgrade it", "filename", filename, "version", version, "current", CurrentVersion)
+		if *cueValue, err = migrateValue(ctx, *cueValue, version); err != nil {
+			return nil, "", ParserError{action: "migrate", err: err, filename: filename}
+		}
+	}
+
 	if err = cueValue.Decode(&workflow); err != nil {
-		return nil, ParserError{
+		return nil, "", ParserError{
 			action:   "convert",
 			err:      err,
 			filename: filename,
 		}
 	}
 
-	return &workflow, nil
+	if err = expandWorkflowSteps(&workflow); err != nil {
+		return nil, "", ParserError{action: "expand the steps of", err: err, filename: filename}
+	}
+
+	if remote.URL != "" {
+		if err = remote.resolveTemplates(context.Background(), workflow.FolderStruct, local); err != nil {
+			return nil, "", ParserError{action: "fetch", err: err, filename: filename}
+		}
+	}
+
+	return &workflow, local, nil
 }
 
-func filenameIsURL(filename string) bool {
-	reg := regexp.MustCompile("^(http|https)")
-	return reg.Match([]byte(filename))
+// fetch downloads the remote workflow, in the cache or in a temporary directory.
+func (p *Parser) fetch(remote RemoteSource) (string, error) {
+	if p.cache != nil {
+		return p.cache.fetch(context.Background(), remote)
+	}
+	dir, err := os.MkdirTemp("", "boot-workflow-")
+	if err != nil {
+		return "", err
+	}
+	p.fetched = append(p.fetched, dir)
+	return remote.fetch(context.Background(), dir)
+}
+
+// Cleanup removes every remote workflow downloaded by the parser in a temporary directory.
+func (p *Parser) Cleanup() error {
+	var errs []error
+	for _, dir := range p.fetched {
+		errs = append(errs, os.RemoveAll(dir))
+	}
+	p.fetched = nil
+	return errors.Join(errs...)
 }
@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/bootengine/boot/internal/model"
@@ -12,6 +13,7 @@ func TestParser_Parse(t *testing.T) {
 	p := parser.NewParser()
 
 	expected := model.Workflow{
+		Version: parser.CurrentVersion,
 		Config: model.Config{
 			CreateRoot:   true,
 			Unrestricted: false,
@@ -85,10 +87,106 @@ func TestParser_Parse(t *testing.T) {
 		},
 	}
 
-	got, err := p.Parse("../mocks/workflow.yaml")
-	td.CmpNoError(t, err)
-	if err == nil {
-		td.Cmp(t, got, &expected)
+	for _, filename := range []string{"workflow.yaml", "workflow.toml", "workflow.cue"} {
+		t.Run(filename, func(t *testing.T) {
+			got, err := p.Parse("../mocks/" + filename)
+			td.CmpNoError(t, err)
+			if err == nil {
+				td.Cmp(t, got, &expected)
+			}
+		})
 	}
+}
+
+func TestParser_ParseSelectVars(t *testing.T) {
+	p := parser.NewParser()
+
+	got, err := p.Parse("../mocks/workflow_select.yaml")
+	td.Require(t).CmpNoError(err)
+
+	td.Cmp(t, got.Vars, model.Vars{
+		{
+			Name:     "project_name",
+			Type:     model.String,
+			Required: true,
+		},
+		{
+			Name:     "frontend",
+			Type:     model.Select,
+			Required: true,
+			Options: []model.VarOption{
+				{Label: "React", Value: "react"},
+				{Label: "Vue.js", Value: "vue"},
+			},
+		},
+		{
+			Name: "linters",
+			Type: model.MultiSelect,
+			Options: []model.VarOption{
+				{Value: "eslint"},
+				{Value: "prettier"},
+			},
+		},
+	})
+
+	_, err = p.Parse("../mocks/workflow_select_invalid.yaml")
+	td.CmpContains(t, err, "options: field is required but not present")
+}
+
+func TestParser_ParseHooks(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+step_groups:
+  notify:
+    params: [{name: message}]
+    steps:
+      - {name: notify, module: slack, action: init, params: ["{{ params.message }}"]}
+hooks:
+  pre_gen:
+    - {name: check node, module: node, action: init}
+  post_gen:
+    - {use: notify, with: {message: "{{ project_name }} is ready"}}
+  on_failure:
+    - {use: notify, with: {message: "{{ failure.message }}"}}
+steps:
+  - {name: npm install, module: npm, action: installLocalDeps}
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Hooks, model.Hooks{
+		PreGen:    []model.Step{{Name: "check node", Module: "node", Action: model.InitAction}},
+		PostGen:   []model.Step{{Name: "notify", Module: "slack", Action: model.InitAction, Params: []string{"{{ project_name }} is ready"}}},
+		OnFailure: []model.Step{{Name: "notify", Module: "slack", Action: model.InitAction, Params: []string{"{{ failure.message }}"}}},
+	})
+
+	writeWorkflows(t, dir, map[string]string{
+		"invalid.yaml": "version: 2\nhooks:\n  post_gen:\n    - {name: a, module: git, action: init, needs: [b]}\n",
+	})
+	_, err = parser.NewParser().Parse(filepath.Join(dir, "invalid.yaml"))
+	td.CmpContains(t, err, `step "a" needs the unknown step b`)
+}
+
+func TestParser_ParseNeeds(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+steps:
+  - {name: frontend, module: npm, action: installLocalDeps, needs: []}
+  - {name: backend, module: go, action: installLocalDeps, needs: []}
+  - {name: commit, module: git, action: commit}
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Steps[0].Needs, &[]string{})
+	td.Cmp(t, got.Steps[1].Needs, &[]string{})
+	td.CmpNil(t, got.Steps[2].Needs)
 
+	deps, err := model.StepDependencies(got.Steps)
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, deps, [][]int{nil, nil, {0, 1}})
 }
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bootengine/boot/internal/model"
+)
+
+const gitPrefix = "git+"
+
+var urlRegex = regexp.MustCompile(`^(https?|git\+[a-z]+)://`)
+
+// FilenameIsURL checks that filename is a remote workflow (an http(s) URL or a git reference) instead of a local path.
+func FilenameIsURL(filename string) bool {
+	return urlRegex.MatchString(filename)
+}
+
+// A RemoteSource is a workflow file hosted on an http server or in a git repository.
+type RemoteSource struct {
+	// URL is the url of the file for http sources, or the url of the repository for git sources.
+	URL string
+	// Git tells that the workflow lives in a git repository.
+	Git bool
+	// Path is the path of the workflow file inside the repository.
+	Path string
+	// Ref is the branch, tag or commit to checkout, the default branch when empty.
+	Ref string
+}
+
+// ParseRemoteSource reads a remote workflow reference. It is either an http(s) URL of a raw file,
+// or a git reference written as git+<repository url>//<path of the workflow>[@<ref>]:
+//
+//	https://example.com/workflows/go-cli.yaml
+//	git+https://github.com/org/repo//workflows/go-cli.yaml@v1.2
+func ParseRemoteSource(ref string) (RemoteSource, error) {
+	if !FilenameIsURL(ref) {
+		return RemoteSource{}, fmt.Errorf("%s is not a remote workflow", ref)
+	}
+	if !strings.HasPrefix(ref, gitPrefix) {
+		return RemoteSource{URL: ref}, nil
+	}
+
+	scheme, rest, _ := strings.Cut(strings.TrimPrefix(ref, gitPrefix), "://")
+	repo, file, ok := strings.Cut(rest, "//")
+	if !ok || file == "" || repo == "" {
+		return RemoteSource{}, fmt.Errorf("invalid git reference %s, expected git+<url>//<path>[@<ref>]", ref)
+	}
+	source := RemoteSource{URL: scheme + "://" + repo, Git: true, Path: file}
+	if i := strings.LastIndex(file, "@"); i >= 0 {
+		source.Path, source.Ref = file[:i], file[i+1:]
+	}
+	if source.Path == "" {
+		return RemoteSource{}, fmt.Errorf("invalid git reference %s, expected git+<url>//<path>[@<ref>]", ref)
+	}
+	if strings.HasPrefix(source.Ref, "-") {
+		// it would be read as an option by git
+		return RemoteSource{}, fmt.Errorf("invalid git reference %s, the ref %q cannot start with -", ref, source.Ref)
+	}
+	return source, nil
+}
+
+// String returns the reference of the source, as accepted by [ParseRemoteSource].
+func (s RemoteSource) String() string {
+	if !s.Git {
+		return s.URL
+	}
+	ref := gitPrefix + s.URL + "//" + s.Path
+	if s.Ref != "" {
+		ref += "@" + s.Ref
+	}
+	return ref
+}
+
+// fetch downloads the workflow in dir and returns the path of the local workflow file.
+func (s RemoteSource) fetch(ctx context.Context, dir string) (string, error) {
+	if s.Git {
+		if err := runGit(ctx, "", "clone", "--quiet", "--", s.URL, dir); err != nil {
+			return "", err
+		}
+		if s.Ref != "" {
+			// the ref is resolved first, so that git never reads it as an option
+			hash, err := outputGit(ctx, dir, "rev-parse", "--verify", "--quiet", "--end-of-options", s.Ref+"^{commit}")
+			if err != nil {
+				return "", fmt.Errorf("unknown ref %s in %s: %w", s.Ref, s.URL, err)
+			}
+			if err = runGit(ctx, dir, "checkout", "--quiet", "--detach", hash); err != nil {
+				return "", err
+			}
+		}
+		return s.localPath(dir)
+	}
+
+	filename, err := s.localPath(dir)
+	if err != nil {
+		return "", err
+	}
+	return filename, download(ctx, s.URL, filename)
+}
+
+// localPath returns where the workflow file is once fetched in dir.
+func (s RemoteSource) localPath(dir string) (string, error) {
+	if s.Git {
+		filename := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+s.Path)))
+		if _, err := os.Stat(filename); err != nil {
+			return "", fmt.Errorf("workflow %s not found in %s", s.Path, s.URL)
+		}
+		return filename, nil
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, path.Base(u.Path)), nil
+}
+
+// resolveTemplates makes the relative template paths of a fetched workflow point to local files.
+// Templates are already in the checkout of git sources, they are downloaded next to the workflow for http sources.
+func (s RemoteSource) resolveTemplates(ctx context.Context, fs model.FolderStruct, filename string) error {
+	return mapTemplatePaths(fs, func(rel string) (string, error) {
+		local := filepath.Join(filepath.Dir(filename), filepath.FromSlash(path.Clean("/"+rel)))
+		if !s.Git {
+			if err := s.downloadTemplate(ctx, rel, local); err != nil {
+				return "", err
+			}
+		}
+		return local, nil
+	})
+}
+
+// downloadTemplate downloads the template at rel, relative to the workflow url, into local.
+// A cached workflow already comes with its templates, they are not downloaded again.
+func (s RemoteSource) downloadTemplate(ctx context.Context, rel, local string) error {
+	if _, err := os.Stat(local); err == nil {
+		return nil
+	}
+	base, err := url.Parse(s.URL)
+	if err != nil {
+		return err
+	}
+	ref, err := url.Parse(filepath.ToSlash(rel))
+	if err != nil {
+		return err
+	}
+	return download(ctx, base.ResolveReference(ref).String(), local)
+}
+
+// download writes the content served at rawURL into filename.
+func download(ctx context.Context, rawURL, filename string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", rawURL, res.Status)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, res.Body)
+	return err
+}
+
+// runGit runs a git command in dir, its error output is part of the returned error.
+func runGit(ctx context.Context, dir string, args ...string) error {
+	_, err := outputGit(ctx, dir, args...)
+	return err
+}
+
+// outputGit runs a git command in dir and returns its trimmed output.
+func outputGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,164 @@
+package parser_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+const remoteWorkflow = `steps:
+  - name: git init
+    module: git
+    action: init
+folder_struct:
+  - main.go:
+      template:
+        filepath: templates/main.go.tmpl
+        engine: jinja2
+`
+
+func TestParseRemoteSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    parser.RemoteSource
+		expectedErr string
+	}{
+		{
+			name:     "http",
+			input:    "https://example.com/workflows/go.yaml",
+			expected: parser.RemoteSource{URL: "https://example.com/workflows/go.yaml"},
+		},
+		{
+			name:     "git with ref",
+			input:    "git+https://github.com/org/repo//workflows/go.yaml@v1.2",
+			expected: parser.RemoteSource{URL: "https://github.com/org/repo", Git: true, Path: "workflows/go.yaml", Ref: "v1.2"},
+		},
+		{
+			name:     "git ssh without ref",
+			input:    "git+ssh://git@github.com/org/repo//go.yaml",
+			expected: parser.RemoteSource{URL: "ssh://git@github.com/org/repo", Git: true, Path: "go.yaml"},
+		},
+		{
+			name:        "git without path",
+			input:       "git+https://github.com/org/repo",
+			expectedErr: "invalid git reference git+https://github.com/org/repo, expected git+<url>//<path>[@<ref>]",
+		},
+		{
+			name:        "git with an option as ref",
+			input:       "git+https://github.com/org/repo//go.yaml@--orphan=x",
+			expectedErr: `invalid git reference git+https://github.com/org/repo//go.yaml@--orphan=x, the ref "--orphan=x" cannot start with -`,
+		},
+		{
+			name:        "local path",
+			input:       "./workflow.yaml",
+			expectedErr: "./workflow.yaml is not a remote workflow",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseRemoteSource(tt.input)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func TestParser_ParseHTTP(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/workflows/go.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(remoteWorkflow))
+	})
+	mux.HandleFunc("/workflows/templates/main.go.tmpl", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("package main"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := parser.NewParser()
+	got, err := p.Parse(server.URL + "/workflows/go.yaml")
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Steps, []model.Step{{Name: "git init", Module: "git", Action: model.InitAction}})
+	assertTemplate(t, got, "package main")
+
+	td.CmpNoError(t, p.Cleanup())
+	_, err = os.Stat(got.FolderStruct[0].(model.File).Filepath)
+	td.CmpTrue(t, os.IsNotExist(err))
+
+	_, err = p.Parse(server.URL + "/missing.yaml")
+	td.CmpContains(t, err, "404 Not Found")
+}
+
+func TestParser_ParseGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// a work tree with two revisions pushed to a bare repository
+	dir := t.TempDir()
+	work, bare := filepath.Join(dir, "work"), filepath.Join(dir, "repo.git")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=boot", "GIT_AUTHOR_EMAIL=boot@example.com",
+			"GIT_COMMITTER_NAME=boot", "GIT_COMMITTER_EMAIL=boot@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		td.Require(t).CmpNoError(err, string(out))
+	}
+	td.Require(t).CmpNoError(os.MkdirAll(filepath.Join(work, "workflows", "templates"), 0755))
+	td.Require(t).CmpNoError(os.WriteFile(filepath.Join(work, "workflows", "go.yaml"), []byte(remoteWorkflow), 0644))
+	td.Require(t).CmpNoError(os.WriteFile(filepath.Join(work, "workflows", "templates", "main.go.tmpl"), []byte("package v1"), 0644))
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	td.Require(t).CmpNoError(os.WriteFile(filepath.Join(work, "workflows", "templates", "main.go.tmpl"), []byte("package v2"), 0644))
+	git("commit", "--quiet", "-am", "v2")
+	git("clone", "--quiet", "--bare", work, bare)
+
+	p := parser.NewParser()
+	defer p.Cleanup()
+
+	got, err := p.Parse("git+file://" + filepath.ToSlash(bare) + "//workflows/go.yaml@v1")
+	td.Require(t).CmpNoError(err)
+	assertTemplate(t, got, "package v1")
+
+	got, err = p.Parse("git+file://" + filepath.ToSlash(bare) + "//workflows/go.yaml")
+	td.Require(t).CmpNoError(err)
+	assertTemplate(t, got, "package v2")
+
+	_, err = p.Parse("git+file://" + filepath.ToSlash(bare) + "//workflows/missing.yaml")
+	td.CmpContains(t, err, "workflow workflows/missing.yaml not found")
+
+	_, err = p.Parse("git+file://" + filepath.ToSlash(bare) + "//workflows/go.yaml@v3")
+	td.CmpContains(t, err, "unknown ref v3 in file://")
+
+	// refs are never given to git as options
+	_, err = p.Parse("git+file://" + filepath.ToSlash(bare) + "//workflows/go.yaml@-f")
+	td.CmpContains(t, err, `the ref "-f" cannot start with -`)
+}
+
+// assertTemplate checks that the template of the single file of the workflow has been fetched.
+func assertTemplate(t *testing.T, got *model.Workflow, content string) {
+	t.Helper()
+	file := got.FolderStruct[0].(model.File)
+	td.CmpTrue(t, filepath.IsAbs(file.Filepath))
+	data, err := os.ReadFile(file.Filepath)
+	td.CmpNoError(t, err)
+	td.Cmp(t, string(data), content)
+}
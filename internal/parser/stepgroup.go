@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/bootengine/boot/internal/expr"
+	"github.com/bootengine/boot/internal/model"
+)
+
+// expandWorkflowSteps expands the step groups used by the steps and hooks of workflow.
+func expandWorkflowSteps(workflow *model.Workflow) error {
+	lists := []struct {
+		path  string
+		steps *[]model.Step
+	}{
+		{"steps", &workflow.Steps},
+		{"hooks.pre_gen", &workflow.Hooks.PreGen},
+		{"hooks.post_gen", &workflow.Hooks.PostGen},
+		{"hooks.on_failure", &workflow.Hooks.OnFailure},
+	}
+	var err error
+	for _, l := range lists {
+		if *l.steps, err = expandSteps(*l.steps, workflow.StepGroups, l.path, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandSteps replaces every step using a step group with the steps of the group, recursively.
+// path locates steps in the workflow for errors, chain lists the groups being expanded.
+func expandSteps(steps []model.Step, groups map[string]model.StepGroup, path string, chain []string) ([]model.Step, error) {
+	var res []model.Step
+	for i, step := range steps {
+		if step.Use == "" {
+			res = append(res, step)
+			continue
+		}
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+
+		group, ok := groups[step.Use]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown step group %q", stepPath, step.Use)
+		}
+		if slices.Contains(chain, step.Use) {
+			return nil, fmt.Errorf("%s: step group %s uses itself: %s -> %s", stepPath, step.Use, strings.Join(chain, " -> "), step.Use)
+		}
+
+		params, err := groupParams(group, step.With)
+		if err != nil {
+			return nil, fmt.Errorf("%s: step group %s: %w", stepPath, step.Use, err)
+		}
+		bound := make([]model.Step, len(group.Steps))
+		for j, s := range group.Steps {
+			if bound[j], err = bindStep(s, map[string]any{"params": params}); err != nil {
+				return nil, fmt.Errorf("step_groups.%s.steps[%d]: %w", step.Use, j, err)
+			}
+		}
+		expanded, err := expandSteps(bound, groups, "step_groups."+step.Use+".steps", append(slices.Clip(chain), step.Use))
+		if err != nil {
+			return nil, err
+		}
+		prefix := step.ID
+		if prefix == "" {
+			prefix = fmt.Sprintf("%s_%d", strings.ReplaceAll(step.Use, "-", "_"), i)
+		}
+		if err = groupStepIDs(expanded, prefix); err != nil {
+			return nil, fmt.Errorf("%s: step group %s: %w", stepPath, step.Use, err)
+		}
+
+		for _, s := range expanded {
+			s.When = joinWhen(step.When, s.When)
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+// groupParams returns the value of every param of group: given by with, or its default.
+func groupParams(group model.StepGroup, with map[string]any) (map[string]any, error) {
+	params := make(map[string]any, len(group.Params))
+	for _, p := range group.Params {
+		if v, ok := with[p.Name]; ok {
+			params[p.Name] = v
+			continue
+		}
+		if p.Default == nil {
+			return nil, fmt.Errorf("missing param %s", p.Name)
+		}
+		params[p.Name] = p.Default
+	}
+	for _, name := range slices.Sorted(maps.Keys(with)) {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("with.%s is not a param of the group", name)
+		}
+	}
+	return params, nil
+}
+
+// bindStep replaces the params of a step group in the expressions of step.
+func bindStep(step model.Step, values map[string]any) (model.Step, error) {
+	var err error
+	if step.Name, err = expr.Bind(step.Name, values); err != nil {
+		return step, err
+	}
+	if step.CurrentWorkingDir, err = expr.Bind(step.CurrentWorkingDir, values); err != nil {
+		return step, err
+	}
+	if step.When, err = expr.BindExpr(step.When, values); err != nil {
+		return step, err
+	}
+	if step.When == "true" {
+		// the condition only relied on params
+		step.When = ""
+	}
+	if step.Params != nil {
+		params := make([]string, len(step.Params))
+		for i, param := range step.Params {
+			if params[i], err = expr.Bind(param, values); err != nil {
+				return step, err
+			}
+		}
+		step.Params = params
+	}
+	if step.With != nil {
+		with := make(map[string]any, len(step.With))
+		for name, v := range step.With {
+			if s, ok := v.(string); ok {
+				if v, err = expr.Bind(s, values); err != nil {
+					return step, err
+				}
+			}
+			with[name] = v
+		}
+		step.With = with
+	}
+	return step, nil
+}
+
+// groupStepIDs prefixes the ids of the steps of a group, and the needs and outputs referring to them,
+// so that a group used twice has distinct step ids. Needs and outputs of steps outside the group are kept.
+func groupStepIDs(steps []model.Step, prefix string) error {
+	var ids []string
+	for _, s := range steps {
+		if s.ID != "" {
+			ids = append(ids, s.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	rename := func(name string) string {
+		rest, ok := strings.CutPrefix(name, "steps.")
+		if ok && slices.ContainsFunc(ids, func(id string) bool { return strings.HasPrefix(rest, id+".") }) {
+			return "steps." + prefix + "." + rest
+		}
+		return name
+	}
+
+	for i := range steps {
+		s := &steps[i]
+		if s.ID != "" {
+			s.ID = prefix + "." + s.ID
+		}
+		if s.Needs != nil {
+			needs := make([]string, len(*s.Needs))
+			for j, need := range *s.Needs {
+				if slices.Contains(ids, need) {
+					need = prefix + "." + need
+				}
+				needs[j] = need
+			}
+			s.Needs = &needs
+		}
+		if err := renameStepOutputs(s, rename); err != nil {
+			return fmt.Errorf("step %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// joinWhen combines the condition of a step using a group with the one of a step of the group.
+func joinWhen(use, step string) string {
+	switch {
+	case use == "":
+		return step
+	case step == "":
+		return use
+	}
+	return fmt.Sprintf("(%s) && (%s)", use, step)
+}
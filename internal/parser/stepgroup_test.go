@@ -0,0 +1,149 @@
+package parser_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/parser"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestParser_ParseStepGroups(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+step_groups:
+  git-setup:
+    params:
+      - name: message
+        default: initial commit
+      - name: remote
+    steps:
+      - {name: git init, module: git, action: init}
+      - use: git-commit
+        with: {message: "{{ params.message }}"}
+      - name: add {{ params.remote }}
+        module: git
+        action: addOrigin
+        params: ["{{ params.remote }}", "{{ project_name }}"]
+  git-commit:
+    params:
+      - name: message
+    steps:
+      - {name: git add, module: git, action: add, params: ["."]}
+      - {name: git commit, module: git, action: commit, params: ["{{ params.message }}"], when: params.message != ""}
+steps:
+  - use: git-setup
+    with: {remote: "git@github.com:bootengine/boot.git"}
+    when: use_git
+  - {name: go mod init, module: go, action: init}
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Steps, []model.Step{
+		{Name: "git init", Module: "git", Action: model.InitAction, When: "use_git"},
+		{Name: "git add", Module: "git", Action: model.VCSAddAction, Params: []string{"."}, When: "use_git"},
+		{Name: "git commit", Module: "git", Action: model.CommitAction, Params: []string{"initial commit"}, When: "use_git"},
+		{
+			Name:   "add git@github.com:bootengine/boot.git",
+			Module: "git",
+			Action: model.AddOriginAction,
+			Params: []string{"git@github.com:bootengine/boot.git", "{{ project_name }}"},
+			When:   "use_git",
+		},
+		{Name: "go mod init", Module: "go", Action: model.InitAction},
+	})
+}
+
+func TestParser_ParseStepGroupsIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": `version: 2
+step_groups:
+  go-service:
+    params:
+      - name: dir
+    steps:
+      - {name: go mod init, id: init, module: go, action: init, cwd: "{{ params.dir }}"}
+      - name: go build
+        id: build
+        module: go
+        action: createFile
+        needs: [init, lint]
+        params: ["{{ steps.init.outputs.version }}", "{{ steps.lint.outputs.report }}"]
+steps:
+  - {name: golangci-lint, id: lint, module: golangci, action: init}
+  - {use: go-service, id: api, with: {dir: api}}
+  - {use: go-service, with: {dir: worker}}
+  - {name: commit, module: git, action: commit, needs: [api.build, go_service_2.build]}
+`,
+	})
+
+	got, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Steps, []model.Step{
+		{Name: "golangci-lint", ID: "lint", Module: "golangci", Action: model.InitAction},
+		{Name: "go mod init", ID: "api.init", Module: "go", Action: model.InitAction, CurrentWorkingDir: "api"},
+		{Name: "go build", ID: "api.build", Module: "go", Action: model.CreateFileAction, Needs: &[]string{"api.init", "lint"},
+			Params: []string{"{{ steps.api.init.outputs.version }}", "{{ steps.lint.outputs.report }}"}},
+		{Name: "go mod init", ID: "go_service_2.init", Module: "go", Action: model.InitAction, CurrentWorkingDir: "worker"},
+		{Name: "go build", ID: "go_service_2.build", Module: "go", Action: model.CreateFileAction, Needs: &[]string{"go_service_2.init", "lint"},
+			Params: []string{"{{ steps.go_service_2.init.outputs.version }}", "{{ steps.lint.outputs.report }}"}},
+		{Name: "commit", Module: "git", Action: model.CommitAction, Needs: &[]string{"api.build", "go_service_2.build"}},
+	})
+}
+
+func TestParser_ParseStepGroupsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		workflow    string
+		expectedErr string
+	}{
+		{
+			name:        "unknown group",
+			workflow:    "version: 2\nsteps:\n  - {name: init, module: git, action: init}\n  - use: gti\n",
+			expectedErr: `steps[1]: unknown step group "gti"`,
+		},
+		{
+			name:        "missing param",
+			workflow:    "version: 2\nstep_groups:\n  commit:\n    params: [{name: message}]\n    steps: [{name: commit, module: git, action: commit}]\nsteps:\n  - use: commit\n",
+			expectedErr: "steps[0]: step group commit: missing param message",
+		},
+		{
+			name:        "unknown param",
+			workflow:    "version: 2\nstep_groups:\n  commit:\n    steps: [{name: commit, module: git, action: commit}]\nsteps:\n  - {use: commit, with: {message: init}}\n",
+			expectedErr: "steps[0]: step group commit: with.message is not a param of the group",
+		},
+		{
+			name:        "recursive group",
+			workflow:    "version: 2\nstep_groups:\n  a:\n    steps: [{use: b}]\n  b:\n    steps: [{use: a}]\nsteps:\n  - use: a\n",
+			expectedErr: "step_groups.b.steps[0]: step group a uses itself: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeWorkflows(t, dir, map[string]string{"workflow.yaml": tt.workflow})
+			filename := filepath.Join(dir, "workflow.yaml")
+
+			_, err := parser.NewParser().Parse(filename)
+			td.CmpString(t, err, "failed to expand the steps of file ("+filename+"): "+tt.expectedErr)
+		})
+	}
+}
+
+func TestParser_CheckStepGroups(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflows(t, dir, map[string]string{
+		"workflow.yaml": "version: 2\nstep_groups:\n  commit:\n    steps: []\nsteps:\n  - {use: commit, module: git}\n",
+	})
+
+	_, err := parser.NewParser().Parse(filepath.Join(dir, "workflow.yaml"))
+	td.CmpString(t, err, strings.ReplaceAll(`failed to check file ({dir}/workflow.yaml): {dir}/workflow.yaml:4:5: step_groups.commit.steps: incompatible list lengths (0 and 2)
+{dir}/workflow.yaml:6:19: steps[0].module: field not allowed`, "{dir}", dir))
+}
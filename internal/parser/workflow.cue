@@ -1,13 +1,80 @@
+// the current version of the workflow format, older versions are kept in schemas/
+#Version: 2
+
+#Include: {
+	from!: string
+	as!: =~"^[a-zA-Z0-9_-]+$"
+	// values of the vars of the included workflow, a string can hold expressions over the vars of the including one
+	with?: {[string]: _}
+	// the vars of the included workflow are renamed as.varname
+	namespace?: bool | *false
+}
+
 #Config : {
 	create_root?: bool | true
 	unrestricted?: bool | false
-	from?: string
+	includes?: [...#Include]
+}
+
+#VarOption: {
+	label?: string
+	value!: string
 }
 
 #Var : {
 	name: string
-	type: "string" | "license" | "password"
-	required: bool
+	type: "string" | "license" | "password" | "select" | "multi" | "computed" | "bool" | "int" | "number" | "list"
+	required: bool | *false
+	options?: [...#VarOption]
+	if type == "select" || type == "multi" {
+		options!: [#VarOption, ...#VarOption]
+	}
+	default?: _
+	description?: string
+	placeholder?: string
+	pattern?: string
+	min_length?: int & >=0
+	max_length?: int & >=0
+	error_message?: string
+	min?: number
+	max?: number
+	if min != _|_ && max != _|_ {
+		max: >=min
+	}
+	if type == "bool" {
+		default?: bool
+	}
+	if type == "int" {
+		default?: int
+	}
+	if type == "number" {
+		default?: number
+	}
+	if type == "int" || type == "number" {
+		if min != _|_ && default != _|_ {
+			default: >=min
+		}
+		if max != _|_ && default != _|_ {
+			default: <=max
+		}
+	}
+	if type == "list" || type == "multi" {
+		default?: [...string]
+	}
+	if type == "string" || type == "password" || type == "select" || type == "license" {
+		default?: string
+	}
+	if pattern != _|_ && default != _|_ && type != "list" {
+		default: =~pattern
+	}
+	if min_length != _|_ && max_length != _|_ {
+		max_length: >=min_length
+	}
+	expr?: string
+	when?: string
+	if type == "computed" {
+		expr!: =~"{{.+}}"
+	}
 }
 
 #Vars: [...#Var]
@@ -15,43 +82,118 @@
 
 #StepAction: "init" | "installLocalDeps" | "installGlobalDeps" | "installDevDeps" | "commit"| "push"| "add"| "addOrigin"| "createFile"| "createFolder"| "writeFile"| "applyTemplate" | "createFolderStruct"
 
-#Step : {
+// steps needs other steps by id, a step without needs waits for every step declared before it,
+// a step with needs: [] waits for none
+#StepID: =~"^[a-zA-Z0-9_-]+$"
+
+// a Go duration, like 90s or 1m30s
+#Duration: =~"^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+
+#ModuleStep: {
 	name!: string
 	module!: !~ "license"
 	action!: #StepAction
 	cwd?: string
 	params?: [...string]
-} | {
+	when?: string
+	id?: #StepID
+	needs?: [...string]
+	retries?: int & >=0
+	timeout?: #Duration
+	continue_on_error?: bool
+}
+
+// the license module has no action, nor any param
+#LicenseStep: {
 	name!: string
 	module!: =~ "license"
+	when?: string
+	id?: #StepID
+	needs?: [...string]
+	continue_on_error?: bool
+}
+
+// a step replaced by the steps of a step group, with gives the params of the group,
+// id prefixes the ids of the steps of the group: <id>.<step id>
+#UseStep: {
+	use!: string
+	id?: #StepID
+	with?: {[string]: _}
+	when?: string
+}
+
+// the variant is picked from the module, so that errors are reported against it only
+#PluginStep: {
+	module!: string
+	if module =~ "license" {
+		#LicenseStep
+	}
+	if module !~ "license" {
+		#ModuleStep
+	}
 }
 
-if #Step.module >= license {
+#Step: {
+	use?: string
+	if use != _|_ {
+		#UseStep
+	}
+	if use == _|_ {
+		#PluginStep
+	}
 }
 
 #Steps: [...#Step]
 
+// steps run before anything is created, once every step is done, and when the generation fails
+#Hooks: {
+	pre_gen?: #Steps
+	post_gen?: #Steps
+	on_failure?: #Steps
+}
+
+#StepGroupParam: {
+	name!: =~"^[a-zA-Z_][a-zA-Z0-9_]*$"
+	default?: _
+}
+
+// a sequence of steps used by name, its steps refer to the params as {{ params.name }}
+#StepGroup: {
+	params?: [...#StepGroupParam]
+	steps!: [#Step, ...#Step]
+}
 
-#TemplateDef: {
-  template: {
+
+#FileSpec: {
+  template?: {
     filepath: string
     engine: string
   }
+  when?: string
 }
-#Filename:=~ "^([a-zA-Z0-9_-]*\\.)+[a-zA-Z0-9_]+$"
-#Complexfile:[#Filename]: #TemplateDef
+// names can use expressions, {{ project_name }}.go
+_filenameRegex: "^(([a-zA-Z0-9_-]|\\{\\{[^}]*\\}\\})*\\.)+([a-zA-Z0-9_]|\\{\\{[^}]*\\}\\})+$"
+#Filename:=~ _filenameRegex
+#Complexfile:[#Filename]: #FileSpec
 #File: #Complexfile | #Filename
 
-#Complexfolder:[string]: #FolderStruct
+#FolderSpec: {
+  when!: string
+  children?: #FolderStruct
+}
+#Complexfolder:[!~ _filenameRegex]: #FolderStruct | #FolderSpec
 #Folder: #Complexfolder | string
 
 #FolderStruct: [...#Folder|#File]
 
 
 #Workflow: {
+	version!: #Version
 	config?: #Config
 	vars?: #Vars
+	step_groups?: {[=~"^[a-zA-Z0-9_-]+$"]: #StepGroup}
 	steps?: #Steps
+	hooks?: #Hooks
 	folder_struct?: #FolderStruct
 }
 
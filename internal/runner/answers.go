@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bootengine/boot/internal/helper"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVarPrefix is the prefix of environment variables used to answer a var without prompting.
+// A var named project_name is answered by BOOT_VAR_PROJECT_NAME.
+const EnvVarPrefix = "BOOT_VAR_"
+
+var envNameReplacer = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// envName returns the name of the environment variable answering the var varName.
+func envName(varName string) string {
+	return EnvVarPrefix + envNameReplacer.ReplaceAllString(strings.ToUpper(varName), "_")
+}
+
+// parseSets converts `name=value` pairs into a map.
+func parseSets(sets []string) (map[string]any, error) {
+	res := make(map[string]any, len(sets))
+	for _, set := range sets {
+		name, value, ok := strings.Cut(set, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set value %q, expected name=value", set)
+		}
+		res[name] = value
+	}
+	return res, nil
+}
+
+// loadAnswersFile reads a yaml or json file containing a map of var values.
+func loadAnswersFile(filename string) (map[string]any, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]any)
+	switch helper.SupportedFileType(strings.TrimPrefix(filepath.Ext(filename), ".")) {
+	case helper.JSON:
+		err = json.Unmarshal(content, &res)
+	case helper.YAML, helper.YML:
+		err = yaml.Unmarshal(content, &res)
+	default:
+		return nil, fmt.Errorf("unsupported answers file type")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file (%s): %w", filename, err)
+	}
+	return res, nil
+}
+
+// providedValues gathers every value given without prompting, for the given var names.
+// Precedence is --set flags, then BOOT_VAR_<NAME> environment variables, then the answers file.
+func (o Options) providedValues(names []string) (map[string]any, error) {
+	res := make(map[string]any)
+
+	if o.AnswersFile != "" {
+		fromFile, err := loadAnswersFile(o.AnswersFile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fromFile {
+			res[k] = v
+		}
+	}
+
+	for _, name := range names {
+		if v, ok := os.LookupEnv(envName(name)); ok {
+			res[name] = v
+		}
+	}
+
+	fromSets, err := parseSets(o.Sets)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range fromSets {
+		res[k] = v
+	}
+
+	return res, nil
+}
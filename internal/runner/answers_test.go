@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_ProvidedValues(t *testing.T) {
+	dir := t.TempDir()
+	answers := filepath.Join(dir, "answers.yaml")
+	err := os.WriteFile(answers, []byte("project_name: from-file\nowner: from-file\nlicense: mit\n"), 0664)
+	td.Require(t).CmpNoError(err)
+
+	t.Setenv("BOOT_VAR_PROJECT_NAME", "from-env")
+	t.Setenv("BOOT_VAR_OWNER", "from-env")
+
+	opts := Options{
+		Sets:        []string{"owner=from-flag"},
+		AnswersFile: answers,
+	}
+
+	got, err := opts.providedValues([]string{"project_name", "owner", "license"})
+	td.CmpNoError(t, err)
+	td.Cmp(t, got, map[string]any{
+		"project_name": "from-env",
+		"owner":        "from-flag",
+		"license":      "mit",
+	})
+}
+
+func Test_ParseSets(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []string
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name:     "valid",
+			input:    []string{"project_name=boot", "empty=", "url=https://a.b/?c=d"},
+			expected: map[string]any{"project_name": "boot", "empty": "", "url": "https://a.b/?c=d"},
+		},
+		{
+			name:        "invalid - no equal sign",
+			input:       []string{"project_name"},
+			expectedErr: `invalid --set value "project_name", expected name=value`,
+		},
+		{
+			name:        "invalid - no name",
+			input:       []string{"=boot"},
+			expectedErr: `invalid --set value "=boot", expected name=value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSets(tt.input)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func Test_EnvName(t *testing.T) {
+	td.Cmp(t, envName("project_name"), "BOOT_VAR_PROJECT_NAME")
+	td.Cmp(t, envName("api.port-number"), "BOOT_VAR_API_PORT_NUMBER")
+}
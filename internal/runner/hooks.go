@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/log"
+)
+
+// The hooks of a workflow, see [model.Hooks].
+const (
+	preGenHook    = "pre_gen"
+	postGenHook   = "post_gen"
+	onFailureHook = "on_failure"
+)
+
+// failureHookTimeout stops the on_failure hook, which runs even when the generation was interrupted.
+var failureHookTimeout = 5 * time.Minute
+
+// A HookError occurs when a step of a hook fails.
+type HookError struct {
+	hook string
+	err  error
+}
+
+func (h HookError) Error() string {
+	return fmt.Sprintf("%s hook failed: %s", h.hook, h.err)
+}
+
+func (h HookError) GetType() string {
+	return "hooks"
+}
+
+func (h HookError) Unwrap() error {
+	return h.err
+}
+
+// runHook runs the steps of hook, like the steps of the workflow but one at a time. They are neither
+// saved in the state of the generation nor in its summary. The project root may not exist before
+// or after a failed generation: only post_gen steps run in it, the others run in the current directory.
+func (r Runner) runHook(hook string, steps []model.Step, values map[string]any) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	r.workflow.Steps = steps
+	r.state, r.summary = nil, nil
+	if hook != postGenHook {
+		r.workflow.Config.CreateRoot = false
+	}
+	r.io.log.Infof("running the %s hook", hook)
+
+	if err := checkSteps(steps, values); err != nil {
+		return HookError{hook: hook, err: err}
+	}
+	deps, err := model.StepDependencies(steps)
+	if err != nil {
+		return HookError{hook: hook, err: err}
+	}
+	err = r.schedule(deps, 1, func(r Runner, i int) error {
+		return failedStep(r.handleStep(i, values), steps[i].Name)
+	})
+	if err != nil {
+		return HookError{hook: hook, err: err}
+	}
+	return nil
+}
+
+// runFailureHook runs the on_failure hook of a generation that failed because of cause,
+// its own failure is only logged. It is not canceled with the generation, but after failureHookTimeout.
+func (r Runner) runFailureHook(cause error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.ctx), failureHookTimeout)
+	defer cancel()
+	r.ctx = ctx
+
+	values, _ := r.ctx.Value(helper.ValueKey{}).(map[string]any)
+	if err := r.runHook(onFailureHook, r.workflow.Hooks.OnFailure, failureValues(values, cause)); err != nil {
+		log.Error(err)
+	}
+}
+
+// failureValues returns values with what made the generation fail, for the on_failure hook:
+// failure.message is the error, failure.step, failure.module and failure.action locate the step that
+// failed, if any, and failure.reason is the error of the step.
+func failureValues(values map[string]any, cause error) map[string]any {
+	failure := map[string]any{"message": cause.Error(), "step": "", "module": "", "action": "", "reason": cause.Error()}
+	var stepErr StepError
+	if errors.As(cause, &stepErr) {
+		failure["step"] = stepErr.step
+		failure["module"] = stepErr.moduleName
+		failure["action"] = stepErr.action
+		failure["reason"] = stepErr.err.Error()
+	}
+
+	res := make(map[string]any, len(values)+1)
+	maps.Copy(res, values)
+	res["failure"] = failure
+	return res
+}
+
+// failedStep sets the name of the step err is about, when it is a [StepError].
+func failedStep(err error, name string) error {
+	if stepErr, ok := err.(StepError); ok && stepErr.step == "" {
+		stepErr.step = name
+		return stepErr
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_Hooks(t *testing.T) {
+	workflow := model.Workflow{
+		Vars:  model.Vars{{Name: "project_name", Type: model.String}},
+		Steps: []model.Step{{Name: "license", Module: "license"}},
+		Hooks: model.Hooks{
+			PreGen:    []model.Step{{Name: "check {{ project_name }}", Module: "license"}},
+			PostGen:   []model.Step{{Name: "notify {{ project_name }}", Module: "license"}},
+			OnFailure: []model.Step{{Name: "report {{ failure.step }}: {{ failure.reason }}", Module: "license"}},
+		},
+	}
+	newRunner := func() *Runner {
+		r := NewRunner(context.Background(), nil, workflow, Options{DryRun: true, NoInput: true, Sets: []string{"project_name=demo"}})
+		r.io.stdout = &bytes.Buffer{}
+		r.io.log = log.NewWithOptions(&bytes.Buffer{}, log.Options{})
+		return r
+	}
+	planned := func(r *Runner) []string {
+		var names []string
+		for _, s := range r.plan.Steps {
+			names = append(names, s.Step.Name)
+		}
+		return names
+	}
+
+	t.Run("generation", func(t *testing.T) {
+		r := newRunner()
+		td.Require(t).CmpNoError(r.Run())
+		td.Cmp(t, planned(r), []string{"check demo", "license", "notify demo"})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		r := newRunner()
+		r.ctx = context.WithValue(r.ctx, helper.ValueKey{}, map[string]any{"project_name": "demo"})
+		r.runFailureHook(StepError{moduleName: "npm", action: "installLocalDeps", err: errors.New("exit status 1"), step: "install"})
+		td.Cmp(t, planned(r), []string{"report install: exit status 1"})
+	})
+
+	t.Run("interrupted generation", func(t *testing.T) {
+		r := newRunner()
+		ctx, cancel := context.WithCancel(context.Background())
+		r.ctx = context.WithValue(ctx, helper.ValueKey{}, map[string]any{"project_name": "demo"})
+		cancel()
+		r.runFailureHook(StepError{moduleName: "npm", action: "installLocalDeps", err: context.Canceled, step: "install"})
+		td.Cmp(t, planned(r), []string{"report install: context canceled"})
+	})
+
+	t.Run("failing hook", func(t *testing.T) {
+		r := newRunner()
+		err := r.runHook(preGenHook, []model.Step{{Name: "check", Module: "license", When: "node"}}, map[string]any{})
+		td.CmpString(t, err, "pre_gen hook failed: failed to execute runner for module license, action : undefined vars: node")
+		var stepErr StepError
+		td.Require(t).True(errors.As(err, &stepErr))
+		td.Cmp(t, stepErr.step, "check")
+	})
+}
+
+func Test_FailureValues(t *testing.T) {
+	values := map[string]any{"project_name": "demo"}
+
+	got := failureValues(values, StepError{moduleName: "git", action: "commit", err: errors.New("nothing to commit"), step: "commit"})
+	td.Cmp(t, got, map[string]any{
+		"project_name": "demo",
+		"failure": map[string]any{
+			"message": "failed to execute runner for module git, action commit: nothing to commit",
+			"step":    "commit",
+			"module":  "git",
+			"action":  "commit",
+			"reason":  "nothing to commit",
+		},
+	})
+	td.Cmp(t, values, map[string]any{"project_name": "demo"}, "values are not modified")
+
+	got = failureValues(nil, ErrMissingVars)
+	td.Cmp(t, got, map[string]any{
+		"failure": map[string]any{
+			"message": ErrMissingVars.Error(),
+			"step":    "",
+			"module":  "",
+			"action":  "",
+			"reason":  ErrMissingVars.Error(),
+		},
+	})
+}
@@ -0,0 +1,22 @@
+package runner
+
+// Options define how a [Runner] collects vars and behaves during the generation.
+type Options struct {
+	// Sets contains the raw `name=value` pairs given with --set.
+	Sets []string
+	// AnswersFile is the path to a yaml or json file holding var values.
+	AnswersFile string
+	// NoInput forbids any prompt, missing required vars end up in a [VarError].
+	NoInput bool
+	// DryRun prints the execution [Plan] instead of running the workflow.
+	DryRun bool
+	// KeepOnFailure disables the rollback of a failed generation.
+	KeepOnFailure bool
+	// WorkflowFile is the path or URL of the workflow, it identifies the saved state of a generation.
+	WorkflowFile string
+	// Resume restarts an interrupted generation from its saved state, skipping the completed steps.
+	Resume bool
+	// Jobs is the number of steps run at the same time, steps run one at a time below 2.
+	// The output of concurrent steps is written once they are done, prefixed with their name.
+	Jobs int
+}
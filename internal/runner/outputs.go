@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/bootengine/boot/internal/expr"
+	"github.com/bootengine/boot/internal/model"
+	extism "github.com/extism/go-sdk"
+)
+
+// stepValues holds the outputs published by the steps with an id. The next steps find them in their
+// values as steps.<id>.outputs.<name>: in their params, cwd, conditions and templates.
+type stepValues struct {
+	mu      sync.Mutex
+	outputs map[string]map[string]any
+}
+
+// newStepValues returns the outputs published by the steps of a resumed generation, or none.
+func newStepValues(outputs map[string]map[string]any) *stepValues {
+	if outputs == nil {
+		return &stepValues{outputs: make(map[string]map[string]any)}
+	}
+	return &stepValues{outputs: maps.Clone(outputs)}
+}
+
+// publish makes the outputs of the step id available to the next steps.
+func (s *stepValues) publish(id string, outputs map[string]any) {
+	if id == "" || len(outputs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[id] = outputs
+}
+
+// with returns values with the outputs published so far under steps. The id of an included step,
+// alias.id, is a path: its outputs are steps.alias.id.outputs.
+func (s *stepValues) with(values map[string]any) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.outputs) == 0 {
+		return values
+	}
+
+	steps := make(map[string]any)
+	for id, outputs := range s.outputs {
+		m := steps
+		for _, part := range strings.Split(id, ".") {
+			next, ok := m[part].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				m[part] = next
+			}
+			m = next
+		}
+		m["outputs"] = outputs
+	}
+	res := maps.Clone(values)
+	res["steps"] = steps
+	return res
+}
+
+// onlyOutputs checks that err is about undefined step outputs only.
+func onlyOutputs(err error) bool {
+	var undefined expr.UndefinedError
+	if !errors.As(err, &undefined) {
+		return false
+	}
+	for _, name := range undefined.Names {
+		if !strings.HasPrefix(name, "steps.") {
+			return false
+		}
+	}
+	return true
+}
+
+// outputFunction is the set_output host function: a plugin calls set_output(name, value) to publish
+// an output of step, stored in outputs.
+func (r Runner) outputFunction(step model.Step, outputs map[string]any) extism.HostFunction {
+	return extism.NewHostFunctionWithStack("set_output", func(_ context.Context, p *extism.CurrentPlugin, stack []uint64) {
+		name, err := p.ReadString(stack[0])
+		if err != nil {
+			r.io.log.Warnf("%s: failed to read the name of an output: %s", step.Name, err)
+			return
+		}
+		value, err := p.ReadString(stack[1])
+		if err != nil {
+			r.io.log.Warnf("%s: failed to read output %s: %s", step.Name, name, err)
+			return
+		}
+		outputs[name] = value
+	}, []extism.ValueType{extism.ValueTypePTR, extism.ValueTypePTR}, nil)
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/bootengine/boot/internal/expr"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_StepValues(t *testing.T) {
+	values := map[string]any{"project_name": "demo"}
+
+	s := newStepValues(nil)
+	td.Cmp(t, s.with(values), values)
+
+	s.publish("go", map[string]any{"version": "1.24"})
+	s.publish("api.commit", map[string]any{"stdout": "a1b2c3"})
+	s.publish("lint", nil)
+	got := s.with(values)
+	td.Cmp(t, got, map[string]any{
+		"project_name": "demo",
+		"steps": map[string]any{
+			"go":  map[string]any{"outputs": map[string]any{"version": "1.24"}},
+			"api": map[string]any{"commit": map[string]any{"outputs": map[string]any{"stdout": "a1b2c3"}}},
+		},
+	})
+	td.Cmp(t, values, map[string]any{"project_name": "demo"}, "values are not modified")
+
+	res, err := expr.Render("{{ steps.go.outputs.version }} {{ steps.api.commit.outputs.stdout }}", got)
+	td.CmpNoError(t, err)
+	td.Cmp(t, res, "1.24 a1b2c3")
+}
+
+func Test_OnlyOutputs(t *testing.T) {
+	td.CmpTrue(t, onlyOutputs(expr.UndefinedError{Names: []string{"steps.go.outputs.version"}}))
+	td.CmpFalse(t, onlyOutputs(expr.UndefinedError{Names: []string{"author", "steps.go.outputs.version"}}))
+	td.CmpFalse(t, onlyOutputs(nil))
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bootengine/boot/internal/model"
+)
+
+// A Plan is what a [Runner] would do, it is computed instead of running the workflow in dry-run mode.
+type Plan struct {
+	Root         string
+	Steps        []PlannedStep
+	FolderStruct model.FolderStruct
+}
+
+// A PlannedStep is a [model.Step] with everything resolved by the runner.
+// The Command is what a cmd or vcs plugin returned, it is never executed. RenderErr tells which
+// templates of a filer step cannot be rendered.
+type PlannedStep struct {
+	model.Step
+	ModuleType model.ModuleType
+	Cwd        string
+	Command    string
+	Skipped    bool
+	RenderErr  error
+}
+
+// Print writes a human readable version of the plan to w.
+func (p Plan) Print(w io.Writer) {
+	fmt.Fprintln(w, "Execution plan (dry-run, nothing has been written nor executed)")
+	if p.Root != "" {
+		fmt.Fprintf(w, "  project root: %s\n", p.Root)
+	}
+
+	fmt.Fprintln(w, "\nSteps:")
+	if len(p.Steps) == 0 {
+		fmt.Fprintln(w, "  no step")
+	}
+	for i, step := range p.Steps {
+		if step.Skipped {
+			fmt.Fprintf(w, "  %d. %s [skipped: %s]\n", i+1, step.Name, step.When)
+			continue
+		}
+		fmt.Fprintf(w, "  %d. %s\n", i+1, step.Name)
+		if step.Module == "license" {
+			fmt.Fprintln(w, "     module: license - creates LICENSE")
+			continue
+		}
+		fmt.Fprintf(w, "     module: %s (%s) - action: %s\n", step.Module, step.ModuleType, step.Action)
+		if step.Scope != "" {
+			fmt.Fprintf(w, "     scope: %s\n", step.Scope)
+		}
+		if len(step.Params) > 0 {
+			fmt.Fprintf(w, "     params: %s\n", strings.Join(step.Params, " "))
+		}
+		switch step.ModuleType {
+		case model.CmdType, model.VCSType:
+			fmt.Fprintf(w, "     cwd: %s\n", step.Cwd)
+			fmt.Fprintf(w, "     command: %s\n", step.Command)
+		case model.FilerType:
+			if step.Action == model.CreateFolderStructAction {
+				fmt.Fprintln(w, "     creates the files below")
+			}
+			if step.RenderErr != nil {
+				fmt.Fprintln(w, "     templates that cannot be rendered:")
+				for _, line := range strings.Split(step.RenderErr.Error(), "\n") {
+					fmt.Fprintf(w, "       %s\n", line)
+				}
+			}
+		}
+	}
+
+	if len(p.FolderStruct) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nFiles:")
+	root := p.Root
+	if root == "" {
+		root = "."
+	}
+	fmt.Fprintf(w, "%s/\n", root)
+	printTree(w, p.FolderStruct, "")
+}
+
+func printTree(w io.Writer, fs model.FolderStruct, indent string) {
+	for i, f := range fs {
+		branch, next := "├── ", "│   "
+		if i == len(fs)-1 {
+			branch, next = "└── ", "    "
+		}
+		if f.IsFile() {
+			file := f.(model.File)
+			if file.TempWrapper != nil {
+				fmt.Fprintf(w, "%s%s%s (template %s, engine %s)\n", indent, branch, file.Name, file.Filepath, file.Engine)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s%s\n", indent, branch, file.Name)
+			continue
+		}
+		folder := f.(model.Folder)
+		fmt.Fprintf(w, "%s%s%s/\n", indent, branch, folder.Name)
+		printTree(w, folder.Filers, indent+next)
+	}
+}
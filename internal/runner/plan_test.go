@@ -0,0 +1,79 @@
+package runner_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/runner"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlan_Print(t *testing.T) {
+	plan := runner.Plan{
+		Root: "my-project",
+		Steps: []runner.PlannedStep{
+			{
+				Step:       model.Step{Name: "git init", Module: "git", Action: model.InitAction, Scope: "services/api"},
+				ModuleType: model.VCSType,
+				Cwd:        "/tmp/my-project/services/api",
+				Command:    "git init",
+			},
+			{
+				Step:    model.Step{Name: "docker init", Module: "docker", Action: model.InitAction, When: "use_docker"},
+				Skipped: true,
+			},
+			{
+				Step:       model.Step{Name: "create folder structure", Module: "filer", Action: model.CreateFolderStructAction},
+				ModuleType: model.FilerType,
+				RenderErr: errors.Join(
+					errors.New("main.go: template plugin exited with error status: unexpected '}'"),
+					errors.New("cmd/root.go: open ./root.go.j2: no such file or directory"),
+				),
+			},
+			{
+				Step: model.Step{Name: "license", Module: "license"},
+			},
+		},
+		FolderStruct: model.FolderStruct{
+			model.Folder{Name: "cmd", Filers: model.FolderStruct{
+				model.Folder{Name: "install"},
+				model.File{Name: "root.go"},
+			}},
+			model.File{Name: "main.go", TempWrapper: &model.TempWrapper{
+				TemplateDef: model.TemplateDef{Filepath: "./main.go.j2", Engine: "jinja"},
+			}},
+		},
+	}
+
+	var sb strings.Builder
+	plan.Print(&sb)
+
+	td.Cmp(t, sb.String(), `Execution plan (dry-run, nothing has been written nor executed)
+  project root: my-project
+
+Steps:
+  1. git init
+     module: git (vcs) - action: init
+     scope: services/api
+     cwd: /tmp/my-project/services/api
+     command: git init
+  2. docker init [skipped: use_docker]
+  3. create folder structure
+     module: filer (filer) - action: createFolderStruct
+     creates the files below
+     templates that cannot be rendered:
+       main.go: template plugin exited with error status: unexpected '}'
+       cmd/root.go: open ./root.go.j2: no such file or directory
+  4. license
+     module: license - creates LICENSE
+
+Files:
+my-project/
+├── cmd/
+│   ├── install/
+│   └── root.go
+└── main.go (template ./main.go.j2, engine jinja)
+`)
+}
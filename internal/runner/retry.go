@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bootengine/boot/internal/model"
+)
+
+var (
+	// retryDelay is the wait before the first retry of a step, it doubles with every retry up to maxRetryDelay.
+	retryDelay    = time.Second
+	maxRetryDelay = 30 * time.Second
+
+	ErrStepTimeout = errors.New("step timed out")
+)
+
+// runAttempts calls run until the step at index i succeeds or has no retries left, each attempt being
+// stopped after the timeout of the step. The result is recorded in the summary; a failure is only
+// returned when the step does not continue on error.
+func (r Runner) runAttempts(i int, step model.Step, run func(r Runner) error) error {
+	var timeout time.Duration
+	if step.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(step.Timeout); err != nil {
+			err = StepError{moduleName: step.Module, action: string(step.Action), err: fmt.Errorf("invalid timeout: %w", err)}
+			r.summary.record(i, StepResult{Step: step, Status: Failed, Err: err})
+			return err
+		}
+	}
+
+	var (
+		start    = time.Now()
+		delay    = retryDelay
+		attempts int
+		err      error
+	)
+	for {
+		attempts++
+		if err = r.attempt(step, timeout, run); err == nil || attempts > step.Retries || r.ctx.Err() != nil {
+			break
+		}
+		r.io.log.Warnf("attempt %d of %d failed, retrying in %s: %s", attempts, step.Retries+1, delay, err)
+		select {
+		case <-r.ctx.Done():
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	res := StepResult{Step: step, Attempts: attempts, Duration: time.Since(start), Err: err}
+	switch {
+	case err == nil && attempts > 1:
+		res.Status = Retried
+	case err == nil:
+		res.Status = Succeeded
+	case r.ctx.Err() != nil:
+		res.Status = Interrupted
+	case step.ContinueOnError:
+		res.Status = Continued
+		r.io.log.Warnf("%s failed, continuing: %s", step.Name, err)
+		err = nil
+	default:
+		res.Status = Failed
+	}
+	r.summary.record(i, res)
+	return err
+}
+
+// attempt calls run once, with a context canceled after timeout when it is positive.
+func (r Runner) attempt(step model.Step, timeout time.Duration, run func(r Runner) error) error {
+	if timeout <= 0 {
+		return run(r)
+	}
+	parent := r.ctx
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	r.ctx = ctx
+
+	err := run(r)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        fmt.Errorf("%w after %s", ErrStepTimeout, timeout),
+		}
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_RunAttempts(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	errInstall := errors.New("npm install failed")
+	tests := []struct {
+		testname    string
+		step        model.Step
+		failures    int
+		expected    StepResult
+		expectedErr error
+	}{
+		{
+			testname: "succeeded",
+			step:     model.Step{Name: "install", Retries: 2},
+			expected: StepResult{Status: Succeeded, Attempts: 1},
+		},
+		{
+			testname: "retried",
+			step:     model.Step{Name: "install", Retries: 2},
+			failures: 2,
+			expected: StepResult{Status: Retried, Attempts: 3},
+		},
+		{
+			testname:    "failed",
+			step:        model.Step{Name: "install", Retries: 1},
+			failures:    3,
+			expected:    StepResult{Status: Failed, Attempts: 2, Err: errInstall},
+			expectedErr: errInstall,
+		},
+		{
+			testname: "continue on error",
+			step:     model.Step{Name: "install", ContinueOnError: true},
+			failures: 1,
+			expected: StepResult{Status: Continued, Attempts: 1, Err: errInstall},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testname, func(t *testing.T) {
+			r := NewRunner(context.Background(), nil, model.Workflow{Steps: []model.Step{tt.step}}, Options{})
+			r.io.log = log.NewWithOptions(&bytes.Buffer{}, log.Options{})
+			r.summary = newSummary(r.workflow.Steps)
+
+			calls := 0
+			err := r.runAttempts(0, tt.step, func(r Runner) error {
+				if calls++; calls <= tt.failures {
+					return errInstall
+				}
+				return nil
+			})
+			td.Cmp(t, err, tt.expectedErr)
+			td.Cmp(t, r.summary.Results[0], td.SStruct(tt.expected, td.StructFields{
+				"Step":     tt.step,
+				"Duration": td.Gte(time.Duration(0)),
+			}))
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		step := model.Step{Name: "install", Module: "npm", Action: model.InstallLocalDepsAction, Timeout: "10ms", Retries: 1}
+		r := NewRunner(context.Background(), nil, model.Workflow{Steps: []model.Step{step}}, Options{})
+		r.io.log = log.NewWithOptions(&bytes.Buffer{}, log.Options{})
+		r.summary = newSummary(r.workflow.Steps)
+
+		err := r.runAttempts(0, step, func(r Runner) error {
+			<-r.ctx.Done()
+			return r.ctx.Err()
+		})
+		td.CmpString(t, err, "failed to execute runner for module npm, action installLocalDeps: step timed out after 10ms")
+		td.CmpTrue(t, errors.Is(err, ErrStepTimeout))
+		td.Cmp(t, r.summary.Results[0].Attempts, 2)
+	})
+}
+
+func Test_SummaryPrint(t *testing.T) {
+	s := newSummary([]model.Step{{Name: "init"}, {Name: "install"}, {Name: "lint"}, {Name: "commit"}})
+	s.record(0, StepResult{Status: Succeeded, Attempts: 1, Duration: 1200 * time.Millisecond})
+	s.record(1, StepResult{Status: Retried, Attempts: 2, Duration: 3 * time.Second})
+	s.record(2, StepResult{Status: Skipped})
+	s.record(3, StepResult{Status: Failed, Attempts: 1, Duration: time.Second, Err: errors.New("nothing to commit")})
+
+	var out bytes.Buffer
+	s.Print(&out)
+	td.Cmp(t, out.String(), `
+Summary:
+  STEP     STATUS     ATTEMPTS  DURATION  ERROR
+  init     succeeded  1         1.2s
+  install  retried    2         3s
+  lint     skipped    -         -
+  commit   failed     1         1s        nothing to commit
+`)
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/log"
+)
+
+// A journal keeps track of every path created by the runner,
+// so that a failed generation can be rolled back to the pre-run state.
+type journal struct {
+	mu      sync.Mutex
+	created []string
+}
+
+// track records path as created by the runner. It must be called before the creation,
+// a path that already exists belongs to the user and is never tracked.
+func (j *journal) track(path string) {
+	if _, err := os.Lstat(path); err == nil {
+		return
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.created = append(j.created, abs)
+}
+
+// paths returns the tracked paths, the most recent first.
+func (j *journal) paths() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	res := make([]string, len(j.created))
+	for i, path := range j.created {
+		res[len(res)-1-i] = path
+	}
+	return res
+}
+
+// rollback removes every tracked path, the most recent first.
+func (j *journal) rollback() error {
+	var errs []error
+	for _, path := range j.paths() {
+		if err := os.RemoveAll(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.created = nil
+	return errors.Join(errs...)
+}
+
+// handleFailure offers to roll back what has been created by a failed generation.
+// Without prompt (--no-input), the rollback is done right away. --keep-on-failure disables it.
+// When the generation state has been saved, it is kept unless a first generation is rolled back.
+func (r Runner) handleFailure(cause error) {
+	paths := r.journal.paths()
+	if len(paths) == 0 {
+		r.hintResume()
+		return
+	}
+	if r.opts.KeepOnFailure {
+		log.Warnf("generation failed, keeping %d created path(s) for debugging", len(paths))
+		r.hintResume()
+		return
+	}
+
+	rollback := true
+	if !r.opts.NoInput {
+		err := huh.NewConfirm().Title("/!\\ The generation failed").
+			Description(cause.Error() + "\n\nDo you want to remove everything boot created ?").
+			Affirmative("Roll back").
+			Negative("Keep it").
+			Value(&rollback).
+			Run()
+		if err != nil {
+			log.Warnf("keeping created paths: %s", HuhError{Err: err})
+			r.hintResume()
+			return
+		}
+	}
+	if !rollback {
+		r.hintResume()
+		return
+	}
+
+	if err := r.journal.rollback(); err != nil {
+		log.Errorf("failed to roll back the generation: %s", err)
+		return
+	}
+	log.Infof("rolled back %d created path(s)", len(paths))
+
+	// a resumed generation only rolls back its own steps, the previous ones are still done
+	if r.state != nil && !r.opts.Resume {
+		if err := r.state.remove(); err != nil {
+			log.Warnf("failed to remove generation state: %s", err)
+		}
+	}
+}
+
+// hintResume tells how to resume the failed generation, when its state has been saved.
+func (r Runner) hintResume() {
+	if r.state != nil {
+		log.Infof("run the same command with --resume to restart from the failed step")
+	}
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_JournalRollback(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	td.Require(t).CmpNoError(os.Mkdir(existing, 0775))
+
+	j := &journal{}
+
+	root := filepath.Join(dir, "my-project")
+	j.track(root)
+	td.Require(t).CmpNoError(os.Mkdir(root, 0775))
+
+	license := filepath.Join(root, "LICENSE")
+	j.track(license)
+	td.Require(t).CmpNoError(os.WriteFile(license, []byte("MIT"), 0664))
+
+	// already there before the generation, it must survive the rollback
+	j.track(existing)
+
+	td.Cmp(t, j.paths(), []string{license, root})
+
+	td.CmpNoError(t, j.rollback())
+	td.CmpEmpty(t, j.paths())
+
+	_, err := os.Stat(root)
+	td.Cmp(t, os.IsNotExist(err), true)
+	_, err = os.Stat(existing)
+	td.CmpNoError(t, err)
+}
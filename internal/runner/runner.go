@@ -1,12 +1,15 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"slices"
@@ -15,7 +18,6 @@ import (
 	"github.com/bootengine/boot/internal/helper"
 	"github.com/bootengine/boot/internal/license"
 	"github.com/bootengine/boot/internal/model"
-	"github.com/bootengine/boot/internal/parser"
 	"github.com/bootengine/boot/internal/usecase"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/log"
@@ -39,10 +41,19 @@ type (
 		ctx      context.Context
 		workflow model.Workflow
 		modCase  *usecase.ModuleUsecase
+		opts     Options
+		plan     *Plan
+		journal  *journal
+		state    *runState
+		io       stepIO
+		summary  *Summary
+		outputs  *stepValues
 	}
 	StepError struct {
 		err                error
 		moduleName, action string
+		// step is the name of the step, once known
+		step string
 	}
 	NoKeepGoingError bool
 	VarError         struct {
@@ -66,6 +77,10 @@ func (s StepError) GetType() string {
 	return "steps"
 }
 
+func (s StepError) Unwrap() error {
+	return s.err
+}
+
 func (v VarError) Error() string {
 	return fmt.Sprintf("failed to handle var %s: %s", v.vars, v.err)
 }
@@ -76,17 +91,24 @@ func (v VarError) GetType() string {
 var (
 	keepGoing            bool = true
 	ErrNoLicenseSelected      = fmt.Errorf("no license selected")
+	ErrMissingVars            = fmt.Errorf("missing required vars while prompting is disabled")
+	ErrMissingPasswords       = fmt.Errorf("passwords are not saved with the generation state, they must be given to resume while prompting is disabled")
 )
 
 func (n NoKeepGoingError) Error() string {
 	return "no keep going"
 }
 
-func NewRunner(use *usecase.ModuleUsecase, workflow model.Workflow) *Runner {
+func NewRunner(ctx context.Context, use *usecase.ModuleUsecase, workflow model.Workflow, opts Options) *Runner {
 	return &Runner{
-		ctx:      context.Background(),
+		ctx:      ctx,
 		modCase:  use,
 		workflow: workflow,
+		opts:     opts,
+		plan:     &Plan{},
+		journal:  &journal{},
+		io:       defaultIO(),
+		outputs:  newStepValues(nil),
 	}
 }
 
@@ -98,6 +120,10 @@ func (r Runner) checkFolderStructCreation() error {
 	if r.workflow.FolderStruct != nil && !slices.ContainsFunc(r.workflow.Steps, func(elem model.Step) bool {
 		return elem.Module == "filer" && elem.Action == model.CreateFolderStructAction
 	}) {
+		if r.opts.NoInput || r.opts.DryRun {
+			log.Warn("a folder_struct is set without explicit step to create it")
+			return nil
+		}
 		err := huh.NewConfirm().Description("a folder_struct is set without explicit step to create it").
 			Title("/!\\ Are you sure ?").
 			Affirmative("Yes !").
@@ -114,213 +140,284 @@ func (r Runner) checkFolderStructCreation() error {
 	return nil
 }
 
-func parseFolder(fs model.FolderStruct, pathMap map[string]string, currentPath string) {
-	for _, fd := range fs {
-		if fd.IsFile() {
-			continue
-		}
-		if strings.HasPrefix(fd.GetName(), "$") {
-			pathMap[strings.TrimPrefix(fd.GetName(), "$")] = currentPath
-			continue
-		}
-
-		parseFolder(fd.(model.Folder).Filers, pathMap, filepath.Join(currentPath, fd.GetName()))
+// Run executes the workflow, between its pre_gen and post_gen hooks. When it fails, the on_failure
+// hook runs, then what has been created can be rolled back.
+func (r Runner) Run() error {
+	err := r.run()
+	if err != nil && !r.opts.DryRun {
+		r.runFailureHook(err)
+		r.handleFailure(err)
 	}
-
+	return err
 }
 
-func (r Runner) Run() error {
-	if len(r.workflow.Config.Includes) > 0 {
-		aliases := make(map[string]model.FolderStruct, len(r.workflow.Config.Includes))
+func (r *Runner) run() error {
+	if r.opts.Resume {
+		if err := r.resume(); err != nil {
+			return err
+		}
+		return r.generate()
+	}
 
-		// calculate base path
-		includedFolder := make(map[string]string)
-		parseFolder(r.workflow.FolderStruct, includedFolder, "")
+	err := r.checkFolderStructCreation()
+	if err != nil {
+		return err
+	}
 
-		// TODO: nested loop are really bad for perf !
-		for _, include := range r.workflow.Config.Includes {
-			work, err := parser.NewParser().Parse(include.From)
-			if err != nil {
-				return err
-			}
-			// Hint: might be able to remove that using a Set (or something that manage uniqueness)
-			for _, v := range work.Vars {
-				if !slices.ContainsFunc(r.workflow.Vars, func(elem model.Var) bool {
-					return v.Name == elem.Name
-				}) {
-					r.workflow.Vars = append([]model.Var{v}, r.workflow.Vars...)
-				}
-			}
+	err = r.handleVars()
+	if err != nil {
+		return err
+	}
 
-			//TODO: included command should be run in specific context
-			// Hint: might be able to remove that using a Set (or something that manage uniqueness)
-			// only need to deduplicate the folder_struct command
-			for _, s := range work.Steps {
-				if s.Action != model.CreateFolderStructAction {
-					s.CurrentWorkingDir = includedFolder[include.As]
-					r.workflow.Steps = append(r.workflow.Steps, s)
-				}
-			}
+	err = r.runHook(preGenHook, r.workflow.Hooks.PreGen, r.ctx.Value(helper.ValueKey{}).(map[string]any))
+	if err != nil {
+		return err
+	}
 
-			aliases[include.As] = work.FolderStruct
+	if !r.opts.DryRun && r.opts.WorkflowFile != "" {
+		path, err := statePath(r.opts.WorkflowFile)
+		if err != nil {
+			return err
+		}
+		r.state = newRunState(path, r.workflow, r.ctx.Value(helper.ValueKey{}).(map[string]any))
+		if err = r.state.save(); err != nil {
+			return fmt.Errorf("failed to save generation state: %w", err)
 		}
-
-		r.workflow.FolderStruct = mergeFolderStruct(r.workflow.FolderStruct, aliases)
 	}
 
-	err := r.checkFolderStructCreation()
+	return r.generate()
+}
+
+// resume loads the state of an interrupted generation, instead of resolving includes and prompting vars.
+func (r *Runner) resume() error {
+	path, err := statePath(r.opts.WorkflowFile)
 	if err != nil {
 		return err
 	}
-
-	err = r.handleVars()
+	r.state, err = loadRunState(path)
 	if err != nil {
 		return err
 	}
+	r.workflow, err = r.state.workflow()
+	if err != nil {
+		return fmt.Errorf("failed to read generation state (%s): %w", path, err)
+	}
+	values, err := r.resumePasswords(r.state.Values)
+	if err != nil {
+		return err
+	}
+	r.ctx = context.WithValue(r.ctx, helper.ValueKey{}, values)
+	r.outputs = newStepValues(r.state.Outputs)
+	log.Infof("resuming the generation, %d step(s) already done", len(r.state.Completed))
+	return nil
+}
 
+// generate creates the project root, runs the steps then the post_gen hook.
+func (r *Runner) generate() error {
+	var err error
 	if r.workflow.Config.CreateRoot {
-		projectName := r.ctx.Value(helper.ValueKey{}).(map[string]any)["project_name"].(string)
-		err = os.MkdirAll(projectName, 0775)
+		projectName, err := r.projectName()
 		if err != nil {
-			return fmt.Errorf("failed to create root project directory: %w", err)
+			return err
 		}
+		if r.opts.DryRun {
+			r.plan.Root = projectName
+		} else {
+			r.journal.track(projectName)
+			if err = os.MkdirAll(projectName, 0775); err != nil {
+				return fmt.Errorf("failed to create root project directory: %w", err)
+			}
+		}
+	}
+
+	r.summary = newSummary(r.workflow.Steps)
+	err = r.handleSteps()
+	if !r.opts.DryRun && len(r.workflow.Steps) > 0 {
+		r.summary.Print(r.io.stdout)
+	}
+	if err != nil {
+		return err
 	}
 
-	return r.handleSteps()
+	err = r.runHook(postGenHook, r.workflow.Hooks.PostGen, r.ctx.Value(helper.ValueKey{}).(map[string]any))
+	if err != nil {
+		return err
+	}
+
+	if r.opts.DryRun {
+		r.plan.Print(os.Stdout)
+	}
+	if r.state != nil {
+		if err = r.state.remove(); err != nil {
+			log.Warnf("failed to remove generation state: %s", err)
+		}
+	}
+	return nil
 }
 
-func mergeFolderStruct(fs model.FolderStruct, content map[string]model.FolderStruct) model.FolderStruct {
-	for i, f := range fs {
-		if f.IsFile() {
-			continue
+// filterFolderStruct removes from fs every file and folder whose `when` condition is false.
+func filterFolderStruct(fs model.FolderStruct, values map[string]any) (model.FolderStruct, error) {
+	res := make(model.FolderStruct, 0, len(fs))
+	for _, f := range fs {
+		enabled, err := isEnabled(f.GetWhen(), values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.GetName(), err)
 		}
-		if !f.IsFile() && !strings.HasPrefix(f.GetName(), "$") {
-			current := f.(model.Folder)
-			current.Filers = mergeFolderStruct(f.(model.Folder).Filers, content)
-			fs[i] = current
+		if !enabled {
+			continue
 		}
-		if !f.IsFile() && strings.HasPrefix(f.GetName(), "$") {
-			name := strings.TrimPrefix(f.GetName(), "$")
-			if merging, ok := content[name]; ok {
-				fs = slices.Replace(fs, i, i+1, merging...)
+		if folder, ok := f.(model.Folder); ok && len(folder.Filers) > 0 {
+			folder.Filers, err = filterFolderStruct(folder.Filers, values)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%w", folder.Name, err)
 			}
+			f = folder
 		}
+		res = append(res, f)
 	}
-
-	return fs
+	return res, nil
 }
 
 func (r *Runner) createLicense() error {
 	contextValue := r.ctx.Value(helper.ValueKey{}).(map[string]any)
-	selectedLicense, ok := contextValue["license"]
-	if !ok {
+	selectedLicense, ok := contextValue["license"].(string)
+	if !ok || selectedLicense == "" {
 		return ErrNoLicenseSelected
 	}
 
-	licensePath := filepath.Join(contextValue["project_name"].(string), "LICENSE")
+	projectName, _ := contextValue["project_name"].(string)
+	licensePath := filepath.Join(projectName, "LICENSE")
 
 	r.ctx = context.WithValue(r.ctx, helper.ValueKey{}, contextValue)
-	content, err := license.GetLicenseContent(r.ctx, selectedLicense.(string))
+	content, err := license.GetLicenseContent(r.ctx, selectedLicense)
 	if err != nil {
 		return err
 	}
 
+	r.journal.track(licensePath)
 	return os.WriteFile(licensePath, []byte(*content), 0664)
 }
 
-func (r *Runner) handleVars() error {
-	values := make(map[string]any)
-	if ok := slices.ContainsFunc(r.workflow.Vars, func(elem model.Var) bool { return elem.Name == "project_name" }); !ok && r.workflow.Config.CreateRoot {
-		err := huh.NewConfirm().Description("the project needs a name, convention is forcing on a 'project_name' var").
-			Title("/!\\ Caution !").
-			Affirmative("Ok").
-			Negative("Cancel").
-			Value(&keepGoing).
-			Run()
+func (r Runner) handleSteps() error {
+	values := r.ctx.Value(helper.ValueKey{}).(map[string]any)
+	if len(r.workflow.FolderStruct) > 0 {
+		fs, err := filterFolderStruct(r.workflow.FolderStruct, values)
 		if err != nil {
-			return HuhError{Err: err}
+			return fmt.Errorf("error happened while evaluating folder_struct conditions: %w", err)
 		}
-		if !keepGoing {
-			return NoKeepGoingError(keepGoing)
+		if fs, err = renderFolderStruct(fs, values); err != nil {
+			return fmt.Errorf("error happened while rendering folder_struct names: %w", err)
 		}
+		r.workflow.FolderStruct = fs
+		r.plan.FolderStruct = r.workflow.FolderStruct
 	}
 
-	for _, v := range r.workflow.Vars {
-		switch v.Type {
-		case model.String:
-			var val string
-			err := huh.NewInput().Title(fmt.Sprintf("what is your %s ?", v.Name)).Value(&val).Run()
-			if err != nil {
-				return HuhError{Err: err}
-			}
-			values[v.Name] = val
-		case model.Password:
-			var val string
-			err := huh.NewInput().Title(fmt.Sprintf("what is your %s ?", v.Name)).EchoMode(huh.EchoModePassword).Value(&val).Run()
-			if err != nil {
-				return HuhError{Err: err}
-			}
-			values[v.Name] = val
-		case model.License:
-			var val string
-			err := huh.NewSelect[string]().Title("what is the prefered license ?").
-				Description(`
-				more info here : https://choosealicense.com/licenses,
-				if the license you want is not in the list, please create an issue () or contribute ()
-				`).
-				Options(
-					options...,
-				).Value(&val).Run()
-			if err != nil {
-				return HuhError{Err: err}
-			}
-			values[v.Name] = val
-		default:
-			return VarError{
-				err:  fmt.Errorf("%s type of var is not managed by boot", v.Type),
-				vars: v.Name,
-			}
-		}
+	if err := checkSteps(r.workflow.Steps, values); err != nil {
+		return err
 	}
-	r.ctx = context.WithValue(r.ctx, helper.ValueKey{}, values)
-
-	return nil
+	deps, err := model.StepDependencies(r.workflow.Steps)
+	if err != nil {
+		return err
+	}
+	jobs := r.opts.Jobs
+	if jobs < 1 || r.opts.DryRun {
+		jobs = 1
+	}
+	return r.schedule(deps, jobs, func(r Runner, i int) error {
+		return failedStep(r.handleStep(i, values), r.workflow.Steps[i].Name)
+	})
 }
 
-func (r Runner) handleSteps() error {
-	if len(r.workflow.FolderStruct) > 0 {
-		r.workflow.FolderStruct = r.getContent(r.workflow.FolderStruct)
+// handleStep runs the step at index i, unless it is already done or its condition is false.
+// The step gets values along with the outputs of the steps done before it.
+func (r Runner) handleStep(i int, values map[string]any) error {
+	step := r.workflow.Steps[i]
+	if err := r.ctx.Err(); err != nil {
+		return fmt.Errorf("generation interrupted: %w", err)
+	}
+	if r.state != nil && r.state.isCompleted(i) {
+		r.io.log.Infof("%s already done", step.Name)
+		r.summary.record(i, StepResult{Status: AlreadyDone})
+		return nil
+	}
+	values = r.outputs.with(values)
+	enabled, err := isEnabled(step.When, values)
+	if r.opts.DryRun && onlyOutputs(err) {
+		// the outputs of the steps are unknown until they run
+		enabled, err = true, nil
 	}
-	jsonFS, err := json.Marshal(r.workflow.FolderStruct)
 	if err != nil {
-		return fmt.Errorf("error happened while preparing folder_struct for filer plugin: %w", err)
+		err = StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
+		}
+		r.summary.record(i, StepResult{Status: Failed, Err: err})
+		return err
+	}
+	if !enabled {
+		r.io.log.Infof("%s skipped", step.Name)
+		r.summary.record(i, StepResult{Status: Skipped})
+		if r.opts.DryRun {
+			r.plan.Steps = append(r.plan.Steps, PlannedStep{Step: step, Skipped: true})
+		}
+		r.completeStep(i, nil)
+		return nil
 	}
 
-	values := r.ctx.Value(helper.ValueKey{}).(map[string]any)
-	jsonValues, err := json.Marshal(values)
-	if err != nil {
-		return fmt.Errorf("error happened while preparing variables for plugins: %w", err)
+	if step, err = renderStep(step, values, r.opts.DryRun); err != nil {
+		err = StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
+		}
+		r.summary.record(i, StepResult{Status: Failed, Err: err})
+		return err
 	}
 
-	for _, step := range r.workflow.Steps {
-		if step.Module == "license" {
-			err := r.createLicense()
-			if err != nil && errors.Is(err, ErrNoLicenseSelected) {
-				// warn user
-			}
-			if err != nil {
-				return StepError{
-					moduleName: step.Module,
-					action:     string(step.Action),
-					err:        err,
-				}
-			}
-			log.Infof("%s succeed", step.Name)
-			continue
+	var outputs map[string]any
+	err = r.runAttempts(i, step, func(r Runner) error {
+		// a failed attempt publishes nothing
+		outputs = make(map[string]any)
+		err := r.runStep(step, values, outputs)
+		if err != nil {
+			outputs = nil
 		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	r.completeStep(i, outputs)
+	return nil
+}
+
+// completeStep publishes the outputs of the step at index i, and saves that it is done,
+// so that a resumed generation skips it.
+func (r Runner) completeStep(i int, outputs map[string]any) {
+	id := r.workflow.Steps[i].ID
+	r.outputs.publish(id, outputs)
+	if r.state == nil {
+		return
+	}
+	if err := r.state.complete(i, id, outputs); err != nil {
+		log.Warnf("failed to save generation state: %s", err)
+	}
+}
 
-		config := make(map[string]string)
-		mod, err := r.modCase.RetrieveModule(r.ctx, step.Module)
+// runStep executes a single step, or adds it to the plan in dry-run mode.
+// The outputs the step publishes are added to outputs.
+func (r Runner) runStep(step model.Step, values map[string]any, outputs map[string]any) error {
+	if step.Module == "license" {
+		if r.opts.DryRun {
+			r.plan.Steps = append(r.plan.Steps, PlannedStep{Step: step})
+			r.plan.FolderStruct = append(r.plan.FolderStruct, model.File{Name: "LICENSE"})
+			return nil
+		}
+		err := r.createLicense()
+		if err != nil && errors.Is(err, ErrNoLicenseSelected) {
+			// warn user
+		}
 		if err != nil {
 			return StepError{
 				moduleName: step.Module,
@@ -328,47 +425,77 @@ func (r Runner) handleSteps() error {
 				err:        err,
 			}
 		}
+		r.io.log.Infof("%s succeed", step.Name)
+		return nil
+	}
 
-		if !slices.Contains(model.Capabilities[mod.Type], step.Action) {
-			return StepError{
-				moduleName: step.Module,
-				action:     string(step.Action),
-				err:        fmt.Errorf("this type of plugin (%s) can't run this action (%s)", mod.Type, step.Action),
-			}
+	config := make(map[string]string)
+	mod, err := r.modCase.RetrieveModule(r.ctx, step.Module)
+	if err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
 		}
+	}
 
-		if mod.Type == model.FilerType {
-			config["folder_struct"] = string(jsonFS)
+	if !slices.Contains(model.Capabilities[mod.Type], step.Action) {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        fmt.Errorf("this type of plugin (%s) can't run this action (%s)", mod.Type, step.Action),
 		}
+	}
 
-		config["values"] = string(jsonValues)
-
-		plugin, err := r.createPlugin(step, *mod, config)
+	if mod.Type == model.FilerType {
+		// templates are rendered once the steps before have published their outputs, in dry-run mode
+		// too: template engines write nothing
+		fs, renderErr := r.getContent(r.workflow.FolderStruct, "", values)
+		if r.opts.DryRun {
+			// filer plugins would write on the disk, they are not even loaded in dry-run mode
+			return r.planStep(step, mod.Type, nil, nil, renderErr)
+		}
+		if renderErr != nil {
+			r.io.log.Errorf("failed to get template: %s", renderErr)
+		}
+		jsonFS, err := json.Marshal(fs)
 		if err != nil {
 			return StepError{
 				moduleName: step.Module,
 				action:     string(step.Action),
-				err:        err,
+				err:        fmt.Errorf("error happened while preparing folder_struct for filer plugin: %w", err),
 			}
 		}
+		config["folder_struct"] = string(jsonFS)
+	}
+	if r.opts.DryRun && mod.Type != model.CmdType && mod.Type != model.VCSType {
+		return r.planStep(step, mod.Type, nil, nil, nil)
+	}
 
-		setLogger(plugin, step)
+	jsonValues, err := json.Marshal(values)
+	if err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        fmt.Errorf("error happened while preparing variables for plugins: %w", err),
+		}
+	}
+	config["values"] = string(jsonValues)
 
-		var params []byte
-		if step.Params != nil {
-			params, err = json.Marshal(step.Params)
-			if err != nil {
-				return StepError{
-					moduleName: step.Module,
-					action:     string(step.Action),
-					err:        err,
-				}
-			}
+	plugin, err := r.createPlugin(step, *mod, config, outputs)
+	if err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
 		}
+	}
 
-		log.Infof("About to run action %q of module %q in %q with params %v", step.Action, step.Module, step.CurrentWorkingDir, params)
+	setLogger(plugin, step)
 
-		exit, out, err := plugin.CallWithContext(r.ctx, string(step.Action), params)
+	var params []byte
+	if step.Params != nil {
+		params, err = json.Marshal(step.Params)
 		if err != nil {
 			return StepError{
 				moduleName: step.Module,
@@ -376,20 +503,84 @@ func (r Runner) handleSteps() error {
 				err:        err,
 			}
 		}
+	}
+
+	if r.opts.DryRun {
+		return r.planStep(step, mod.Type, plugin, params, nil)
+	}
+
+	if err = r.createScope(step); err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
+		}
+	}
 
-		if err = r.handleOutput(step, mod.Type, plugin, exit, out); err != nil {
+	if mod.Type == model.FilerType {
+		r.trackFilerOutput(step)
+	}
+
+	r.io.log.Infof("About to run action %q of module %q in %q with params %v", step.Action, step.Module, filepath.Join(step.Scope, step.CurrentWorkingDir), params)
+
+	exit, out, err := plugin.CallWithContext(r.ctx, string(step.Action), params)
+	if err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
+		}
+	}
+
+	if err = r.handleOutput(step, mod.Type, plugin, exit, out, outputs); err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
+		}
+	}
+	return nil
+}
+
+// planStep adds the step to the plan. Only cmd and vcs plugins are called, since they
+// only return the command to execute.
+func (r Runner) planStep(step model.Step, modType model.ModuleType, plugin *extism.Plugin, params []byte, renderErr error) error {
+	cwd, err := r.stepCwd(step)
+	if err != nil {
+		return StepError{
+			moduleName: step.Module,
+			action:     string(step.Action),
+			err:        err,
+		}
+	}
+	planned := PlannedStep{
+		Step:       step,
+		ModuleType: modType,
+		Cwd:        cwd,
+		RenderErr:  renderErr,
+	}
+
+	if modType == model.CmdType || modType == model.VCSType {
+		exit, out, err := plugin.CallWithContext(r.ctx, string(step.Action), params)
+		if err == nil && exit != 0 {
+			err = errors.New(plugin.GetErrorWithContext(r.ctx))
+		}
+		if err != nil {
 			return StepError{
 				moduleName: step.Module,
 				action:     string(step.Action),
 				err:        err,
 			}
 		}
+		planned.Command = string(out)
 	}
 
+	r.plan.Steps = append(r.plan.Steps, planned)
 	return nil
 }
 
-func (r Runner) createPlugin(step model.Step, mod model.Module, config map[string]string) (*extism.Plugin, error) {
+// createPlugin loads the plugin of step, the outputs it publishes are added to outputs.
+func (r Runner) createPlugin(step model.Step, mod model.Module, config map[string]string, outputs map[string]any) (*extism.Plugin, error) {
 	manifest := extism.Manifest{
 		Wasm: []extism.Wasm{
 			extism.WasmFile{
@@ -400,20 +591,20 @@ func (r Runner) createPlugin(step model.Step, mod model.Module, config map[strin
 		Config: config,
 	}
 
-	projectName := r.ctx.Value(helper.ValueKey{}).(map[string]any)["project_name"].(string)
-
 	if mod.Type == model.FilerType { //|| mod.Type == model.VCSType
 		manifest.AllowedPaths = map[string]string{
-			projectName: "/app",
+			r.stepRoot(step): "/app",
 		}
 	}
 
 	pluginConfig := extism.PluginConfig{
 		ModuleConfig: wazero.NewModuleConfig(),
-		EnableWasi:   true,
+		// so that the timeout of the step, or the failure of another one, stops the call
+		RuntimeConfig: wazero.NewRuntimeConfig().WithCloseOnContextDone(true),
+		EnableWasi:    true,
 	}
 
-	plugin, err := extism.NewPlugin(r.ctx, manifest, pluginConfig, nil)
+	plugin, err := extism.NewPlugin(r.ctx, manifest, pluginConfig, []extism.HostFunction{r.outputFunction(step, outputs)})
 	if err != nil {
 		return nil, StepError{
 			moduleName: step.Module,
@@ -424,7 +615,9 @@ func (r Runner) createPlugin(step model.Step, mod model.Module, config map[strin
 	return plugin, err
 }
 
-func (r Runner) handleOutput(step model.Step, modType model.ModuleType, plugin *extism.Plugin, exit uint32, out []byte) error {
+// handleOutput checks the result of a filer plugin, or executes the command returned by a cmd or vcs plugin.
+// The stdout of the command of a step with an id is published as its stdout output.
+func (r Runner) handleOutput(step model.Step, modType model.ModuleType, plugin *extism.Plugin, exit uint32, out []byte, outputs map[string]any) error {
 	switch modType {
 	case model.FilerType:
 		if exit != 0 {
@@ -435,28 +628,10 @@ func (r Runner) handleOutput(step model.Step, modType model.ModuleType, plugin *
 				err:        errors.New(errString),
 			}
 		}
-		log.Infof("%s succeed", step.Name)
+		r.io.log.Infof("%s succeed", step.Name)
 	// log success
 	case model.CmdType, model.VCSType:
-		cwd, err := func() (string, error) {
-			cwd, err := os.Getwd()
-			if err != nil {
-				return "", err
-			}
-			pn := r.ctx.Value(helper.ValueKey{}).(map[string]any)["project_name"].(string)
-
-			if r.workflow.Config.CreateRoot {
-				cwd = filepath.Join(cwd, pn)
-			}
-
-			if step.CurrentWorkingDir != "" {
-				if filepath.IsAbs(step.CurrentWorkingDir) {
-					return step.CurrentWorkingDir, nil
-				}
-				return filepath.Join(cwd, step.CurrentWorkingDir), nil
-			}
-			return cwd, nil
-		}()
+		cwd, err := r.stepCwd(step)
 		if err != nil {
 			return StepError{
 				moduleName: step.Module,
@@ -464,7 +639,13 @@ func (r Runner) handleOutput(step model.Step, modType model.ModuleType, plugin *
 				err:        err,
 			}
 		}
-		err = r.executeCommand(string(out), cwd)
+		var stdout bytes.Buffer
+		w := r.io.stdout
+		if step.ID != "" {
+			// only captured when it can be used, commands then don't write to a terminal
+			w = io.MultiWriter(w, &stdout)
+		}
+		err = r.executeCommand(string(out), cwd, w)
 		if err != nil {
 			return StepError{
 				moduleName: step.Module,
@@ -472,11 +653,85 @@ func (r Runner) handleOutput(step model.Step, modType model.ModuleType, plugin *
 				err:        err,
 			}
 		}
+		if step.ID != "" {
+			outputs["stdout"] = strings.TrimRight(stdout.String(), "\n")
+		}
 	}
 	return nil
 }
 
-func (r Runner) executeCommand(cmd string, cwd string) error {
+// projectRoot returns the root folder of the generated project, relative to the current directory.
+func (r Runner) projectRoot() string {
+	if r.workflow.Config.CreateRoot {
+		projectName, _ := r.projectName()
+		return projectName
+	}
+	return "."
+}
+
+// projectName returns the project_name value, the name of the project root.
+func (r Runner) projectName() (string, error) {
+	values, _ := r.ctx.Value(helper.ValueKey{}).(map[string]any)
+	projectName, ok := values["project_name"].(string)
+	if !ok || projectName == "" {
+		return "", VarError{
+			err:  ErrMissingVars,
+			vars: "project_name",
+		}
+	}
+	return projectName, nil
+}
+
+// stepRoot returns the folder a step operates on: the project root, or the $alias folder
+// of the workflow the step was included from.
+func (r Runner) stepRoot(step model.Step) string {
+	return filepath.Join(r.projectRoot(), step.Scope)
+}
+
+// createScope creates the $alias folder of an included step when the folder_struct did not.
+func (r Runner) createScope(step model.Step) error {
+	if step.Scope == "" {
+		return nil
+	}
+	dir := r.projectRoot()
+	for _, part := range strings.Split(filepath.ToSlash(step.Scope), "/") {
+		dir = filepath.Join(dir, part)
+		r.journal.track(dir)
+	}
+	return os.MkdirAll(dir, 0775)
+}
+
+// trackFilerOutput records what a filer step is about to create in its root.
+func (r Runner) trackFilerOutput(step model.Step) {
+	root := r.stepRoot(step)
+
+	switch step.Action {
+	case model.CreateFolderStructAction:
+		for _, f := range r.workflow.FolderStruct {
+			r.journal.track(filepath.Join(root, f.GetName()))
+		}
+	case model.CreateFileAction, model.CreateFolderAction:
+		for _, param := range step.Params {
+			r.journal.track(filepath.Join(root, param))
+		}
+	}
+}
+
+// stepCwd computes the directory a cmd or vcs step runs in:
+// the root of the step (see [Runner.stepRoot]), joined with the step's cwd.
+func (r Runner) stepCwd(step model.Step) (string, error) {
+	if filepath.IsAbs(step.CurrentWorkingDir) {
+		return step.CurrentWorkingDir, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cwd, r.stepRoot(step), step.CurrentWorkingDir), nil
+}
+
+// executeCommand runs cmd in cwd, its standard output is written to stdout.
+func (r Runner) executeCommand(cmd string, cwd string, stdout io.Writer) error {
 	if !r.workflow.Config.Unrestricted && !r.checkCommandContent(cmd) {
 		return fmt.Errorf("plugin is trying to execute a suspicious command: %s", cmd)
 	}
@@ -507,16 +762,16 @@ func (r Runner) executeCommand(cmd string, cwd string) error {
 	}
 	_, err := exec.LookPath(exe)
 	if err != nil {
-		fmt.Println("failed to find exec :", exe)
+		r.io.log.Errorf("failed to find exec : %s", exe)
 		return err
 	}
 
 	command := exec.CommandContext(r.ctx, exe, splittedCmd[1:]...)
-	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	command.Stdin, command.Stdout, command.Stderr = r.io.stdin, stdout, r.io.stderr
 	command.Dir = cwd
 
 	// TODO: Should print here or in the caller the command and the cwd
-	log.Infof("about to run command %q", cmd)
+	r.io.log.Infof("about to run command %q", cmd)
 
 	return command.Run()
 }
@@ -529,8 +784,8 @@ func (r Runner) checkCommandContent(cmd string) bool {
 }
 
 // TODO: better error handling
-func (r Runner) getTemplate(tempEngine, tempPath string) (string, error) {
-	values := r.ctx.Value(helper.ValueKey{}).(map[string]any)
+func (r Runner) getTemplate(tempEngine, tempPath, namespace string, values map[string]any) (string, error) {
+	values = scopedValues(values, namespace)
 	jsonValues, err := json.Marshal(values)
 	if err != nil {
 		return "", fmt.Errorf("error happened while preparing variables for plugins: %w", err)
@@ -617,17 +872,21 @@ func setLogger(plugin *extism.Plugin, step model.Step) {
 	})
 }
 
-func (r Runner) getContent(fs model.FolderStruct) model.FolderStruct {
+// getContent returns a copy of fs where the content of every file with a template is rendered with values.
+// dir is the path of fs in the folder_struct, it locates the templates that cannot be rendered in the error.
+func (r Runner) getContent(fs model.FolderStruct, dir string, values map[string]any) (model.FolderStruct, error) {
 	l := log.NewWithOptions(os.Stdout, log.Options{Level: log.DebugLevel, Prefix: "get-content"})
+	fs = slices.Clone(fs)
+	var errs []error
 	for i, f := range fs {
 		if f.IsFile() {
 			file := f.(model.File)
 			l.Debug(file.Name)
 			if file.TempWrapper != nil {
-				content, err := r.getTemplate(file.Engine, file.Filepath)
+				content, err := r.getTemplate(file.Engine, file.Filepath, file.Namespace, values)
 				l.Debugf("content = %s", content)
 				if err != nil {
-					l.Errorf("failed to get template: %s", err.Error())
+					errs = append(errs, fmt.Errorf("%s: %w", path.Join(dir, file.Name), err))
 				}
 				file.Content = content
 				fs[i] = file
@@ -637,9 +896,13 @@ func (r Runner) getContent(fs model.FolderStruct) model.FolderStruct {
 			folder := f.(model.Folder)
 			l.Debug(folder.Name)
 			if len(folder.Filers) > 0 {
-				folder.Filers = r.getContent(folder.Filers)
+				var err error
+				if folder.Filers, err = r.getContent(folder.Filers, path.Join(dir, folder.Name), values); err != nil {
+					errs = append(errs, err)
+				}
+				fs[i] = folder
 			}
 		}
 	}
-	return fs
+	return fs, errors.Join(errs...)
 }
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_StepCwd(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	td.Require(t).CmpNoError(err)
+	td.Require(t).CmpNoError(os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+	dir, err = os.Getwd()
+	td.Require(t).CmpNoError(err)
+
+	ctx := context.WithValue(context.Background(), helper.ValueKey{}, map[string]any{"project_name": "shop"})
+	tests := []struct {
+		name       string
+		createRoot bool
+		step       model.Step
+		expected   string
+	}{
+		{name: "project root", createRoot: true, step: model.Step{}, expected: "shop"},
+		{name: "cwd", createRoot: true, step: model.Step{CurrentWorkingDir: "cmd"}, expected: "shop/cmd"},
+		{name: "included", createRoot: true, step: model.Step{Scope: "services/api"}, expected: "shop/services/api"},
+		{name: "included with cwd", createRoot: true, step: model.Step{Scope: "services/api", CurrentWorkingDir: "cmd"}, expected: "shop/services/api/cmd"},
+		{name: "included without root", step: model.Step{Scope: "api", CurrentWorkingDir: "cmd"}, expected: "api/cmd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(ctx, nil, model.Workflow{Config: model.Config{CreateRoot: tt.createRoot}}, Options{})
+			got, err := r.stepCwd(tt.step)
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, filepath.Join(dir, tt.expected))
+		})
+	}
+
+	t.Run("create scope", func(t *testing.T) {
+		r := NewRunner(ctx, nil, model.Workflow{Config: model.Config{CreateRoot: true}}, Options{})
+		td.Require(t).CmpNoError(os.Mkdir("shop", 0775))
+
+		td.CmpNoError(t, r.createScope(model.Step{Scope: "services/api"}))
+		td.CmpNoError(t, r.createScope(model.Step{}))
+		td.Cmp(t, r.journal.paths(), []string{
+			filepath.Join(dir, "shop/services/api"),
+			filepath.Join(dir, "shop/services"),
+		})
+		_, err := os.Stat("shop/services/api")
+		td.CmpNoError(t, err)
+	})
+}
@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// stepIO is where a step writes its logs and the output of its commands.
+type stepIO struct {
+	log    *log.Logger
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// defaultIO is the terminal, used when steps run one at a time.
+func defaultIO() stepIO {
+	return stepIO{log: log.Default(), stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+}
+
+// schedule calls run for every step, at most jobs at a time, each one once the steps it depends on
+// are done. The first failure cancels the context of the steps running, and no other step is started.
+func (r Runner) schedule(deps [][]int, jobs int, run func(r Runner, i int) error) error {
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+
+	waiting := make([]int, len(deps))
+	dependents := make([][]int, len(deps))
+	var ready []int
+	for i, d := range deps {
+		waiting[i] = len(d)
+		for _, j := range d {
+			dependents[j] = append(dependents[j], i)
+		}
+		if len(d) == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	type result struct {
+		i   int
+		err error
+	}
+	var (
+		results  = make(chan result)
+		outputs  = newStepOutputs(len(deps), r.io.stdout)
+		running  int
+		firstErr error
+	)
+	for len(ready) > 0 || running > 0 {
+		for firstErr == nil && running < jobs && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+
+			sr := r
+			sr.ctx = ctx
+			if jobs > 1 {
+				sr.io = outputs.io(i, r.workflow.Steps[i].Name, r.io.log)
+			} else {
+				sr.io.log = r.io.log.WithPrefix(r.workflow.Steps[i].Name)
+			}
+			running++
+			go func() { results <- result{i: i, err: run(sr, i)} }()
+		}
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		outputs.finish(res.i)
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = res.err
+				cancel()
+			}
+			continue
+		}
+		for _, j := range dependents[res.i] {
+			if waiting[j]--; waiting[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+		// the steps are started in the order they are declared in
+		slices.Sort(ready)
+	}
+	outputs.flush()
+	return firstErr
+}
+
+// stepOutputs gathers the output of steps running concurrently. The output of a step is written
+// once it and every step declared before it are done, so outputs are never interleaved.
+type stepOutputs struct {
+	mu       sync.Mutex
+	w        io.Writer
+	buffers  []*lockedBuffer
+	finished []bool
+	next     int
+}
+
+func newStepOutputs(n int, w io.Writer) *stepOutputs {
+	return &stepOutputs{w: w, buffers: make([]*lockedBuffer, n), finished: make([]bool, n)}
+}
+
+// io returns the [stepIO] of the step i: its logs, written by a copy of logger, and the output of its
+// commands are prefixed with its name, and it can't read from the terminal.
+func (o *stepOutputs) io(i int, name string, logger *log.Logger) stepIO {
+	buf := &lockedBuffer{}
+	o.mu.Lock()
+	o.buffers[i] = buf
+	o.mu.Unlock()
+
+	logger = logger.WithPrefix(name)
+	logger.SetOutput(buf)
+	out := &prefixWriter{w: buf, prefix: name + " | "}
+	return stepIO{log: logger, stdin: nil, stdout: out, stderr: out}
+}
+
+// finish marks the step i as done, and writes the output of the steps done in declaration order.
+func (o *stepOutputs) finish(i int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.finished[i] = true
+	for ; o.next < len(o.finished) && o.finished[o.next]; o.next++ {
+		o.write(o.next)
+	}
+}
+
+// flush writes the output of every step done, once the scheduling stopped.
+func (o *stepOutputs) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for ; o.next < len(o.finished); o.next++ {
+		if o.finished[o.next] {
+			o.write(o.next)
+		}
+	}
+}
+
+func (o *stepOutputs) write(i int) {
+	if buf := o.buffers[i]; buf != nil {
+		buf.mu.Lock()
+		defer buf.mu.Unlock()
+		o.w.Write(buf.b.Bytes())
+	}
+}
+
+// lockedBuffer is a buffer that commands and loggers can write to concurrently.
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+// prefixWriter writes every line to w, prefixed with prefix.
+type prefixWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	prefix  string
+	midLine bool
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !p.midLine {
+			buf.WriteString(p.prefix)
+		}
+		buf.Write(line)
+		p.midLine = line[len(line)-1] != '\n'
+	}
+	if _, err := p.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_Schedule(t *testing.T) {
+	steps := []model.Step{
+		{Name: "init", ID: "init"},
+		{Name: "frontend", ID: "frontend", Needs: &[]string{"init"}},
+		{Name: "backend", ID: "backend", Needs: &[]string{"init"}},
+		{Name: "commit"},
+	}
+	deps, err := model.StepDependencies(steps)
+	td.Require(t).CmpNoError(err)
+
+	newRunner := func(out *bytes.Buffer) Runner {
+		r := NewRunner(context.Background(), nil, model.Workflow{Steps: steps}, Options{})
+		r.io.stdout = out
+		r.io.log = log.NewWithOptions(out, log.Options{})
+		return *r
+	}
+
+	t.Run("concurrent steps", func(t *testing.T) {
+		var (
+			out     bytes.Buffer
+			mu      sync.Mutex
+			order   []string
+			started = make(chan struct{})
+		)
+		err := newRunner(&out).schedule(deps, 2, func(r Runner, i int) error {
+			name := steps[i].Name
+			switch name {
+			case "frontend":
+				// backend runs at the same time, frontend waits for it to start
+				<-started
+			case "backend":
+				close(started)
+				time.Sleep(10 * time.Millisecond)
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			r.io.log.Info("done")
+			fmt.Fprintf(r.io.stdout, "output of %s\n", name)
+			return nil
+		})
+		td.CmpNoError(t, err)
+		td.Cmp(t, order[0], "init")
+		td.Cmp(t, order[3], "commit")
+		// the output is in declaration order, whatever the order the steps ran in
+		td.Cmp(t, out.String(), `INFO init: done
+init | output of init
+INFO frontend: done
+frontend | output of frontend
+INFO backend: done
+backend | output of backend
+INFO commit: done
+commit | output of commit
+`)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		var out bytes.Buffer
+		errBackend := errors.New("npm install failed")
+		var commitRan bool
+		err := newRunner(&out).schedule(deps, 2, func(r Runner, i int) error {
+			switch steps[i].Name {
+			case "frontend":
+				// cancelled by the failure of backend
+				<-r.ctx.Done()
+				return r.ctx.Err()
+			case "backend":
+				return errBackend
+			case "commit":
+				commitRan = true
+			}
+			return nil
+		})
+		td.Cmp(t, err, errBackend)
+		td.CmpFalse(t, commitRan)
+	})
+
+	t.Run("one at a time", func(t *testing.T) {
+		var (
+			out   bytes.Buffer
+			order []string
+		)
+		err := newRunner(&out).schedule(deps, 1, func(r Runner, i int) error {
+			order = append(order, steps[i].Name)
+			r.io.log.Info("done")
+			return nil
+		})
+		td.CmpNoError(t, err)
+		td.Cmp(t, order, []string{"init", "frontend", "backend", "commit"})
+		td.Cmp(t, out.String(), "INFO init: done\nINFO frontend: done\nINFO backend: done\nINFO commit: done\n")
+	})
+}
+
+func Test_ScheduleWithoutNeeds(t *testing.T) {
+	steps := []model.Step{
+		{Name: "frontend", Needs: &[]string{}},
+		{Name: "backend", Needs: &[]string{}},
+		{Name: "commit"},
+	}
+	deps, err := model.StepDependencies(steps)
+	td.Require(t).CmpNoError(err)
+
+	r := NewRunner(context.Background(), nil, model.Workflow{Steps: steps}, Options{})
+	r.io.stdout = &bytes.Buffer{}
+	r.io.log = log.NewWithOptions(&bytes.Buffer{}, log.Options{})
+
+	// frontend and backend each wait for the other to start, they only both end when run concurrently
+	var running sync.WaitGroup
+	running.Add(2)
+	err = r.schedule(deps, 2, func(r Runner, i int) error {
+		if steps[i].Name == "commit" {
+			return nil
+		}
+		running.Done()
+		done := make(chan struct{})
+		go func() {
+			running.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(time.Second):
+			return fmt.Errorf("%s ran alone", steps[i].Name)
+		}
+	})
+	td.CmpNoError(t, err)
+}
+
+func Test_PrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{w: &out, prefix: "npm | "}
+	fmt.Fprint(w, "added 3 packages")
+	fmt.Fprint(w, " in 2s\nfound 0 vulnerabilities\n\n")
+	td.Cmp(t, out.String(), "npm | added 3 packages in 2s\nnpm | found 0 vulnerabilities\nnpm | \n")
+}
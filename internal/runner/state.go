@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/bootengine/boot/internal/parser"
+)
+
+// ErrNoRunState is returned when resuming a generation that has no saved state.
+var ErrNoRunState = errors.New("no interrupted generation to resume for this workflow in this directory")
+
+// A runState is persisted during a generation so that it can be resumed after a failure.
+// It holds the resolved workflow, the collected values but the passwords, the index of completed
+// steps and the outputs they published.
+type runState struct {
+	Workflow  model.GeneratingWorkflow  `json:"workflow"`
+	Values    map[string]any            `json:"values"`
+	Completed []int                     `json:"completed"`
+	Outputs   map[string]map[string]any `json:"outputs,omitempty"`
+
+	path string
+	// mu guards Completed and Outputs, steps complete concurrently
+	mu sync.Mutex
+}
+
+// statePath returns where the state of a generation of workflowFile, run from the current directory, is stored.
+func statePath(workflowFile string) (string, error) {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	source := workflowFile
+	if abs, err := filepath.Abs(workflowFile); err == nil && !parser.FilenameIsURL(workflowFile) {
+		source = abs
+	}
+
+	sum := sha256.Sum256([]byte(source + "\n" + cwd))
+	return filepath.Join(config, "bootengine", "runs", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func newRunState(path string, workflow model.Workflow, values map[string]any) *runState {
+	saved := maps.Clone(values)
+	for _, v := range workflow.Vars {
+		if v.Type == model.Password && saved[v.Name] != nil {
+			delete(saved, v.Name)
+		}
+	}
+	return &runState{
+		Workflow:  workflow.Convert(),
+		Values:    saved,
+		Completed: []int{},
+		path:      path,
+	}
+}
+
+func loadRunState(path string) (*runState, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoRunState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := runState{path: path}
+	if err = json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("failed to read generation state (%s): %w", path, err)
+	}
+	return &state, nil
+}
+
+// workflow returns the resolved workflow saved in the state.
+func (s *runState) workflow() (model.Workflow, error) {
+	var workflow model.Workflow
+	data, err := json.Marshal(s.Workflow)
+	if err != nil {
+		return workflow, err
+	}
+	err = json.Unmarshal(data, &workflow)
+	return workflow, err
+}
+
+func (s *runState) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// complete marks the step at index i as done, and saves the outputs of the step id.
+func (s *runState) complete(i int, id string, outputs map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Completed = append(s.Completed, i)
+	if id != "" && len(outputs) > 0 {
+		if s.Outputs == nil {
+			s.Outputs = make(map[string]map[string]any)
+		}
+		s.Outputs[id] = outputs
+	}
+	return s.save()
+}
+
+func (s *runState) isCompleted(i int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.Completed, i)
+}
+
+func (s *runState) remove() error {
+	err := os.Remove(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootengine/boot/internal/model"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_RunState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs", "state.json")
+
+	_, err := loadRunState(path)
+	td.CmpErrorIs(t, err, ErrNoRunState)
+
+	workflow := model.Workflow{
+		Config: model.Config{CreateRoot: true},
+		Vars: []model.Var{
+			{Name: "project_name", Type: model.String, Required: true},
+			{Name: "db_password", Type: model.Password},
+		},
+		Steps: []model.Step{
+			{Name: "init", Module: "git", Action: "init", ID: "init"},
+			{Name: "install", Module: "npm", Action: "install", When: "use_npm"},
+		},
+		FolderStruct: model.FolderStruct{
+			model.File{Name: "README.md"},
+			model.Folder{Name: "api", Filers: model.FolderStruct{
+				model.File{Name: "main.go", When: "api.port", TempWrapper: &model.TempWrapper{
+					TemplateDef: model.TemplateDef{Engine: "gotmpl", Filepath: "main.tmpl"},
+					Namespace:   "api",
+				}},
+			}},
+		},
+	}
+	values := map[string]any{"project_name": "demo", "use_npm": true, "db_password": "s3cret"}
+	state := newRunState(path, workflow, values)
+	td.Require(t).CmpNoError(state.save())
+	td.Require(t).CmpNoError(state.complete(0, "init", map[string]any{"stdout": "Initialized empty Git repository"}))
+
+	loaded, err := loadRunState(path)
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, loaded.Values, map[string]any{"project_name": "demo", "use_npm": true}, "passwords are not saved")
+	td.Cmp(t, values["db_password"], "s3cret", "values are not modified")
+	td.CmpTrue(t, loaded.isCompleted(0))
+	td.CmpFalse(t, loaded.isCompleted(1))
+	td.Cmp(t, loaded.Outputs, map[string]map[string]any{"init": {"stdout": "Initialized empty Git repository"}})
+
+	got, err := loaded.workflow()
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got.Steps, workflow.Steps)
+	td.Cmp(t, got.Vars, workflow.Vars)
+	td.Cmp(t, got.Config.CreateRoot, true)
+	td.Cmp(t, got.FolderStruct, workflow.FolderStruct)
+
+	td.CmpNoError(t, loaded.remove())
+	_, err = loadRunState(path)
+	td.CmpErrorIs(t, err, ErrNoRunState)
+}
+
+func Test_ResumePasswords(t *testing.T) {
+	workflow := model.Workflow{
+		Vars: []model.Var{
+			{Name: "project_name", Type: model.String, Required: true},
+			{Name: "db_password", Type: model.Password},
+			{Name: "api_token", Type: model.Password},
+		},
+	}
+	// api_token was not set, it is saved as nil
+	saved := map[string]any{"project_name": "demo", "api_token": nil}
+
+	r := NewRunner(context.Background(), nil, workflow, Options{NoInput: true, Sets: []string{"db_password=s3cret"}})
+	got, err := r.resumePasswords(saved)
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, got, map[string]any{"project_name": "demo", "db_password": "s3cret", "api_token": nil})
+	td.Cmp(t, saved, map[string]any{"project_name": "demo", "api_token": nil}, "saved values are not modified")
+
+	r = NewRunner(context.Background(), nil, workflow, Options{NoInput: true})
+	_, err = r.resumePasswords(saved)
+	td.Cmp(t, err, VarError{err: ErrMissingPasswords, vars: "db_password"})
+}
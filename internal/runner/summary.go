@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bootengine/boot/internal/model"
+)
+
+// StepStatus is what happened to a step during a generation.
+type StepStatus string
+
+const (
+	Succeeded StepStatus = "succeeded"
+	// Retried steps succeeded after at least one failed attempt.
+	Retried StepStatus = "retried"
+	Skipped StepStatus = "skipped"
+	Failed  StepStatus = "failed"
+	// Continued steps failed, the generation went on since they allow it with continue_on_error.
+	Continued StepStatus = "failed, continued"
+	// Interrupted steps were stopped by the failure of another step, or by the user.
+	Interrupted StepStatus = "interrupted"
+	AlreadyDone StepStatus = "already done"
+	NotRun      StepStatus = "not run"
+)
+
+// A StepResult is the outcome of a step, Attempts is 0 when the step was not run.
+type StepResult struct {
+	Step     model.Step
+	Status   StepStatus
+	Attempts int
+	Duration time.Duration
+	Err      error
+}
+
+// A Summary holds the result of every step of a generation, it is printed once the generation is over.
+type Summary struct {
+	mu      sync.Mutex
+	Results []StepResult
+}
+
+func newSummary(steps []model.Step) *Summary {
+	s := &Summary{Results: make([]StepResult, len(steps))}
+	for i, step := range steps {
+		s.Results[i] = StepResult{Step: step, Status: NotRun}
+	}
+	return s
+}
+
+// record sets the result of the step at index i. Without a Step, res keeps the one of the workflow,
+// the rendered step is given once it is known.
+func (s *Summary) record(i int, res StepResult) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if res.Step.Name == "" {
+		res.Step = s.Results[i].Step
+	}
+	s.Results[i] = res
+}
+
+// Print writes the summary as a table to w.
+func (s *Summary) Print(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintln(w, "\nSummary:")
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  STEP\tSTATUS\tATTEMPTS\tDURATION\tERROR")
+	for _, res := range s.Results {
+		attempts, duration := "-", "-"
+		if res.Attempts > 0 {
+			attempts = strconv.Itoa(res.Attempts)
+			duration = res.Duration.Round(time.Millisecond).String()
+		}
+		errString := ""
+		if res.Err != nil {
+			errString = res.Err.Error()
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", res.Step.Name, res.Status, attempts, duration, errString)
+	}
+	tw.Flush()
+	// the cells of the last column are padded too
+	for _, line := range strings.SplitAfter(table.String(), "\n") {
+		if line != "" {
+			fmt.Fprintln(w, strings.TrimRight(line, " \n"))
+		}
+	}
+}
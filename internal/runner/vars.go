@@ -0,0 +1,611 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bootengine/boot/internal/expr"
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/charmbracelet/huh"
+)
+
+// handleVars collects the value of every var of the workflow. A var disabled by its when, or left
+// unset while prompting is disabled, is nil, so the conditions using it are false.
+func (r *Runner) handleVars() error {
+	values := make(map[string]any)
+	if ok := slices.ContainsFunc(r.workflow.Vars, func(elem model.Var) bool { return elem.Name == "project_name" }); !ok && r.workflow.Config.CreateRoot && !r.opts.NoInput {
+		err := huh.NewConfirm().Description("the project needs a name, convention is forcing on a 'project_name' var").
+			Title("/!\\ Caution !").
+			Affirmative("Ok").
+			Negative("Cancel").
+			Value(&keepGoing).
+			Run()
+		if err != nil {
+			return HuhError{Err: err}
+		}
+		if !keepGoing {
+			return NoKeepGoingError(keepGoing)
+		}
+	}
+
+	names := make([]string, len(r.workflow.Vars))
+	for i, v := range r.workflow.Vars {
+		names[i] = v.Name
+	}
+	provided, err := r.opts.providedValues(names)
+	if err != nil {
+		return VarError{
+			err:  err,
+			vars: strings.Join(names, ", "),
+		}
+	}
+
+	var missing []string
+	for _, v := range r.workflow.Vars {
+		if v.Type == model.Computed {
+			continue
+		}
+		enabled, err := isEnabled(v.When, values)
+		if err != nil {
+			return VarError{
+				err:  err,
+				vars: v.Name,
+			}
+		}
+		if !enabled {
+			values[v.Name] = nil
+			continue
+		}
+
+		var val any
+		raw, ok := provided[v.Name]
+		if v.Value != nil {
+			if raw, err = boundValue(v.Value, values); err != nil {
+				return VarError{
+					err:  err,
+					vars: v.Name,
+				}
+			}
+			ok = true
+		}
+		if !ok && r.opts.NoInput && v.Default != nil {
+			raw, ok = v.Default, true
+		}
+
+		switch {
+		case ok:
+			val, err = coerceValue(v, raw)
+			if err == nil {
+				err = v.Validate(val)
+			}
+			if err != nil {
+				return VarError{
+					err:  err,
+					vars: v.Name,
+				}
+			}
+		case r.opts.NoInput:
+			if v.Required {
+				missing = append(missing, v.Name)
+			}
+			values[v.Name] = nil
+			continue
+		default:
+			val, err = promptVar(v)
+			if err != nil {
+				return err
+			}
+		}
+		values[v.Name] = val
+	}
+
+	if len(missing) > 0 {
+		return VarError{
+			err:  ErrMissingVars,
+			vars: strings.Join(missing, ", "),
+		}
+	}
+
+	if err := computeVars(r.workflow.Vars, values); err != nil {
+		return err
+	}
+	r.ctx = context.WithValue(r.ctx, helper.ValueKey{}, values)
+
+	if r.workflow.Config.CreateRoot {
+		if _, err := r.projectName(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resumePasswords returns the values saved in the state of an interrupted generation with the passwords,
+// which are not saved: they are provided again or prompted.
+func (r *Runner) resumePasswords(values map[string]any) (map[string]any, error) {
+	var names []string
+	for _, v := range r.workflow.Vars {
+		if _, ok := values[v.Name]; !ok && v.Type == model.Password {
+			names = append(names, v.Name)
+		}
+	}
+	if len(names) == 0 {
+		return values, nil
+	}
+	provided, err := r.opts.providedValues(names)
+	if err != nil {
+		return nil, VarError{
+			err:  err,
+			vars: strings.Join(names, ", "),
+		}
+	}
+
+	res := maps.Clone(values)
+	var missing []string
+	for _, v := range r.workflow.Vars {
+		if !slices.Contains(names, v.Name) {
+			continue
+		}
+		var val any
+		raw, ok := provided[v.Name]
+		switch {
+		case ok:
+			val, err = coerceValue(v, raw)
+			if err == nil {
+				err = v.Validate(val)
+			}
+			if err != nil {
+				return nil, VarError{
+					err:  err,
+					vars: v.Name,
+				}
+			}
+		case r.opts.NoInput:
+			missing = append(missing, v.Name)
+			continue
+		default:
+			if val, err = promptVar(v); err != nil {
+				return nil, err
+			}
+		}
+		res[v.Name] = val
+	}
+
+	if len(missing) > 0 {
+		return nil, VarError{
+			err:  ErrMissingPasswords,
+			vars: strings.Join(missing, ", "),
+		}
+	}
+	return res, nil
+}
+
+// boundValue returns the value given to a var by an include, expressions of a string value are rendered.
+func boundValue(value any, values map[string]any) (any, error) {
+	if s, ok := value.(string); ok && expr.HasExpr(s) {
+		return expr.Render(s, values)
+	}
+	return value, nil
+}
+
+// scopedValues returns values where the vars of namespace can also be used without their namespace.
+func scopedValues(values map[string]any, namespace string) map[string]any {
+	if namespace == "" {
+		return values
+	}
+	res := maps.Clone(values)
+	for k, v := range values {
+		if name, ok := strings.CutPrefix(k, namespace+"."); ok {
+			res[name] = v
+		}
+	}
+	return res
+}
+
+// renderStep renders the expressions of the name, cwd and params of step. Every undefined var is
+// reported at once in an [expr.UndefinedError]. In dry-run mode the outputs of the steps are unknown,
+// the expressions they are used in are kept as is.
+func renderStep(step model.Step, values map[string]any, dryRun bool) (model.Step, error) {
+	render := expr.Render
+	if dryRun {
+		render = expr.Bind
+	}
+	fields := []*string{&step.Name, &step.CurrentWorkingDir}
+	if step.Params != nil {
+		step.Params = slices.Clone(step.Params)
+		for i := range step.Params {
+			fields = append(fields, &step.Params[i])
+		}
+	}
+
+	var undefined []string
+	for _, field := range fields {
+		res, err := render(*field, values)
+		var u expr.UndefinedError
+		if errors.As(err, &u) {
+			undefined = append(undefined, u.Names...)
+			continue
+		}
+		if err != nil {
+			return step, err
+		}
+		*field = res
+	}
+	if len(undefined) > 0 {
+		slices.Sort(undefined)
+		return step, expr.UndefinedError{Names: slices.Compact(undefined)}
+	}
+	return step, nil
+}
+
+// checkSteps renders the steps whose condition is not false before any of them runs, so that the vars
+// they use and are undefined are reported at once. The outputs of the steps are not known yet.
+func checkSteps(steps []model.Step, values map[string]any) error {
+	var errs []error
+	for _, step := range steps {
+		if enabled, err := isEnabled(step.When, values); err == nil && !enabled {
+			continue
+		}
+		_, err := renderStep(step, values, false)
+		var u expr.UndefinedError
+		if errors.As(err, &u) {
+			u.Names = slices.DeleteFunc(u.Names, func(name string) bool {
+				return strings.HasPrefix(name, "steps.")
+			})
+			if len(u.Names) == 0 {
+				continue
+			}
+			err = u
+		}
+		if err != nil {
+			errs = append(errs, StepError{
+				moduleName: step.Module,
+				action:     string(step.Action),
+				err:        fmt.Errorf("step %q: %w", step.Name, err),
+				step:       step.Name,
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// renderFolderStruct renders the expressions of the names of fs. Every undefined var is reported at
+// once in an [expr.UndefinedError].
+func renderFolderStruct(fs model.FolderStruct, values map[string]any) (model.FolderStruct, error) {
+	var undefined []string
+	var walk func(fs model.FolderStruct) (model.FolderStruct, error)
+	walk = func(fs model.FolderStruct) (model.FolderStruct, error) {
+		if len(fs) == 0 {
+			return fs, nil
+		}
+		res := make(model.FolderStruct, 0, len(fs))
+		for _, f := range fs {
+			name, err := expr.Render(f.GetName(), values)
+			var u expr.UndefinedError
+			if errors.As(err, &u) {
+				undefined = append(undefined, u.Names...)
+			} else if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.GetName(), err)
+			}
+			if f.IsFile() {
+				file := f.(model.File)
+				file.Name = name
+				res = append(res, file)
+				continue
+			}
+			folder := f.(model.Folder)
+			folder.Name = name
+			if folder.Filers, err = walk(folder.Filers); err != nil {
+				return nil, err
+			}
+			res = append(res, folder)
+		}
+		return res, nil
+	}
+
+	res, err := walk(fs)
+	if err != nil {
+		return nil, err
+	}
+	if len(undefined) > 0 {
+		slices.Sort(undefined)
+		return nil, expr.UndefinedError{Names: slices.Compact(undefined)}
+	}
+	return res, nil
+}
+
+// isEnabled evaluates a `when` condition, an empty condition is always true.
+func isEnabled(when string, values map[string]any) (bool, error) {
+	if when == "" {
+		return true, nil
+	}
+	return expr.EvalBool(when, values)
+}
+
+// computeVars evaluates the expression of every computed var, in declaration order,
+// so that a computed var can rely on the ones declared before it. A disabled computed var is nil.
+func computeVars(vars model.Vars, values map[string]any) error {
+	for _, v := range vars {
+		if v.Type != model.Computed {
+			continue
+		}
+		ok, err := isEnabled(v.When, values)
+		if err != nil {
+			return VarError{
+				err:  err,
+				vars: v.Name,
+			}
+		}
+		if !ok {
+			values[v.Name] = nil
+			continue
+		}
+		val, err := expr.Render(v.Expr, values)
+		if err == nil {
+			err = v.Validate(val)
+		}
+		if err != nil {
+			return VarError{
+				err:  err,
+				vars: v.Name,
+			}
+		}
+		values[v.Name] = val
+	}
+	return nil
+}
+
+// promptVar asks the user for the value of the var v.
+// The Default of the var is used as the initial value, the answer must pass [model.Var.Validate].
+func promptVar(v model.Var) (any, error) {
+	var (
+		val      string
+		validate = func(s string) error { return v.Validate(s) }
+	)
+	if v.Default != nil {
+		val = fmt.Sprint(v.Default)
+	}
+
+	switch v.Type {
+	case model.String:
+		err := huh.NewInput().Title(fmt.Sprintf("what is your %s ?", v.Name)).
+			Description(v.Description).
+			Placeholder(v.Placeholder).
+			Validate(validate).
+			Value(&val).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+	case model.Password:
+		err := huh.NewInput().Title(fmt.Sprintf("what is your %s ?", v.Name)).
+			Description(v.Description).
+			Placeholder(v.Placeholder).
+			EchoMode(huh.EchoModePassword).
+			Validate(validate).
+			Value(&val).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+	case model.License:
+		description := v.Description
+		if description == "" {
+			description = `
+				more info here : https://choosealicense.com/licenses,
+				if the license you want is not in the list, please create an issue () or contribute ()
+				`
+		}
+		err := huh.NewSelect[string]().Title("what is the prefered license ?").
+			Description(description).
+			Options(
+				options...,
+			).Value(&val).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+	case model.Select:
+		err := huh.NewSelect[string]().Title(fmt.Sprintf("what is your %s ?", v.Name)).
+			Description(v.Description).
+			Options(varOptions(v)...).
+			Value(&val).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+	case model.MultiSelect:
+		var vals []string
+		if v.Default != nil {
+			if def, err := coerceValue(v, v.Default); err == nil {
+				vals = def.([]string)
+			}
+		}
+		err := huh.NewMultiSelect[string]().Title(fmt.Sprintf("what are your %s ?", v.Name)).
+			Description(v.Description).
+			Options(varOptions(v)...).
+			Validate(func(s []string) error { return v.Validate(s) }).
+			Value(&vals).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+		if vals == nil {
+			vals = []string{}
+		}
+		return vals, nil
+	case model.Bool:
+		var confirmed bool
+		if v.Default != nil {
+			if def, err := coerceValue(v, v.Default); err == nil {
+				confirmed = def.(bool)
+			}
+		}
+		err := huh.NewConfirm().Title(fmt.Sprintf("%s ?", v.Name)).
+			Description(v.Description).
+			Affirmative("Yes").
+			Negative("No").
+			Value(&confirmed).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+		return confirmed, nil
+	case model.Int, model.Number:
+		err := huh.NewInput().Title(fmt.Sprintf("what is your %s ?", v.Name)).
+			Description(v.Description).
+			Placeholder(v.Placeholder).
+			Validate(func(s string) error {
+				if s == "" && !v.Required {
+					return nil
+				}
+				num, err := coerceValue(v, s)
+				if err != nil {
+					return err
+				}
+				return v.Validate(num)
+			}).
+			Value(&val).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+		if val == "" {
+			return nil, nil
+		}
+		return coerceValue(v, val)
+	case model.List:
+		if v.Default != nil {
+			val = strings.Join(toStringList(v.Default), "\n")
+		}
+		split := func(s string) []string {
+			vals := []string{}
+			for _, line := range strings.Split(s, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					vals = append(vals, line)
+				}
+			}
+			return vals
+		}
+		description := "one entry per line."
+		if v.Description != "" {
+			description = fmt.Sprintf("%s\n%s", v.Description, description)
+		}
+		err := huh.NewText().Title(fmt.Sprintf("what are your %s ?", v.Name)).
+			Description(description).
+			Placeholder(v.Placeholder).
+			Validate(func(s string) error { return v.Validate(split(s)) }).
+			Value(&val).Run()
+		if err != nil {
+			return nil, HuhError{Err: err}
+		}
+		return split(val), nil
+	default:
+		return nil, VarError{
+			err:  fmt.Errorf("%s type of var is not managed by boot", v.Type),
+			vars: v.Name,
+		}
+	}
+	return val, nil
+}
+
+// varOptions converts the options of a select or multi var into huh options.
+func varOptions(v model.Var) []huh.Option[string] {
+	res := make([]huh.Option[string], len(v.Options))
+	for i, o := range v.Options {
+		res[i] = huh.NewOption(o.GetLabel(), o.Value)
+	}
+	return res
+}
+
+// toStringList converts a list given without prompting to a []string.
+// Flags and environment variables give a comma separated list.
+func toStringList(raw any) []string {
+	vals := []string{}
+	switch raw := raw.(type) {
+	case []any:
+		for _, elem := range raw {
+			vals = append(vals, fmt.Sprint(elem))
+		}
+	case []string:
+		vals = append(vals, raw...)
+	default:
+		for _, elem := range strings.Split(fmt.Sprint(raw), ",") {
+			if elem = strings.TrimSpace(elem); elem != "" {
+				vals = append(vals, elem)
+			}
+		}
+	}
+	return vals
+}
+
+// toNumber converts a number given without prompting to a float64.
+func toNumber(raw any) (float64, error) {
+	switch raw := raw.(type) {
+	case int:
+		return float64(raw), nil
+	case int64:
+		return float64(raw), nil
+	case float64:
+		return raw, nil
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(raw)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", fmt.Sprint(raw))
+	}
+	return val, nil
+}
+
+// coerceValue converts a value given without prompting (flag, env, answers file) to the type of the var v.
+func coerceValue(v model.Var, raw any) (any, error) {
+	switch v.Type {
+	case model.String, model.Password:
+		return fmt.Sprint(raw), nil
+	case model.License:
+		val := fmt.Sprint(raw)
+		if !slices.ContainsFunc(options, func(o huh.Option[string]) bool { return o.Value == val }) {
+			return nil, fmt.Errorf("license %s is not available", val)
+		}
+		return val, nil
+	case model.Select:
+		val := fmt.Sprint(raw)
+		if !v.HasOption(val) {
+			return nil, fmt.Errorf("%q is not one of the options", val)
+		}
+		return val, nil
+	case model.MultiSelect:
+		vals := toStringList(raw)
+		for _, val := range vals {
+			if !v.HasOption(val) {
+				return nil, fmt.Errorf("%q is not one of the options", val)
+			}
+		}
+		return vals, nil
+	case model.List:
+		return toStringList(raw), nil
+	case model.Bool:
+		switch raw := raw.(type) {
+		case bool:
+			return raw, nil
+		default:
+			val, err := strconv.ParseBool(strings.TrimSpace(fmt.Sprint(raw)))
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a boolean", fmt.Sprint(raw))
+			}
+			return val, nil
+		}
+	case model.Int:
+		val, err := toNumber(raw)
+		if err != nil {
+			return nil, err
+		}
+		if val != math.Trunc(val) {
+			return nil, fmt.Errorf("%v is not an integer", raw)
+		}
+		return int(val), nil
+	case model.Number:
+		return toNumber(raw)
+	default:
+		return nil, fmt.Errorf("%s type of var is not managed by boot", v.Type)
+	}
+}
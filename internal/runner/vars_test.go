@@ -0,0 +1,385 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bootengine/boot/internal/helper"
+	"github.com/bootengine/boot/internal/model"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func Test_CoerceValue(t *testing.T) {
+	options := []model.VarOption{{Value: "react"}, {Value: "vue"}, {Value: "svelte"}}
+
+	tests := []struct {
+		name        string
+		v           model.Var
+		raw         any
+		expected    any
+		expectedErr string
+	}{
+		{
+			name:     "string",
+			v:        model.Var{Name: "project_name", Type: model.String},
+			raw:      "boot",
+			expected: "boot",
+		},
+		{
+			name:     "license",
+			v:        model.Var{Name: "license", Type: model.License},
+			raw:      "mit",
+			expected: "mit",
+		},
+		{
+			name:        "license - unknown",
+			v:           model.Var{Name: "license", Type: model.License},
+			raw:         "wtfpl",
+			expectedErr: "license wtfpl is not available",
+		},
+		{
+			name:     "select",
+			v:        model.Var{Name: "frontend", Type: model.Select, Options: options},
+			raw:      "vue",
+			expected: "vue",
+		},
+		{
+			name:        "select - not an option",
+			v:           model.Var{Name: "frontend", Type: model.Select, Options: options},
+			raw:         "angular",
+			expectedErr: `"angular" is not one of the options`,
+		},
+		{
+			name:     "multi - from answers file",
+			v:        model.Var{Name: "frontends", Type: model.MultiSelect, Options: options},
+			raw:      []any{"react", "svelte"},
+			expected: []string{"react", "svelte"},
+		},
+		{
+			name:     "multi - from flag",
+			v:        model.Var{Name: "frontends", Type: model.MultiSelect, Options: options},
+			raw:      "react, vue",
+			expected: []string{"react", "vue"},
+		},
+		{
+			name:     "multi - empty",
+			v:        model.Var{Name: "frontends", Type: model.MultiSelect, Options: options},
+			raw:      "",
+			expected: []string{},
+		},
+		{
+			name:        "multi - not an option",
+			v:           model.Var{Name: "frontends", Type: model.MultiSelect, Options: options},
+			raw:         "react,angular",
+			expectedErr: `"angular" is not one of the options`,
+		},
+		{
+			name:     "bool - from answers file",
+			v:        model.Var{Name: "use_docker", Type: model.Bool},
+			raw:      true,
+			expected: true,
+		},
+		{
+			name:     "bool - from flag",
+			v:        model.Var{Name: "use_docker", Type: model.Bool},
+			raw:      "false",
+			expected: false,
+		},
+		{
+			name:        "bool - invalid",
+			v:           model.Var{Name: "use_docker", Type: model.Bool},
+			raw:         "maybe",
+			expectedErr: `"maybe" is not a boolean`,
+		},
+		{
+			name:     "int - from flag",
+			v:        model.Var{Name: "port", Type: model.Int},
+			raw:      "8080",
+			expected: 8080,
+		},
+		{
+			name:     "int - from json answers file",
+			v:        model.Var{Name: "port", Type: model.Int},
+			raw:      float64(8080),
+			expected: 8080,
+		},
+		{
+			name:        "int - not an integer",
+			v:           model.Var{Name: "port", Type: model.Int},
+			raw:         "80.5",
+			expectedErr: "80.5 is not an integer",
+		},
+		{
+			name:     "number",
+			v:        model.Var{Name: "ratio", Type: model.Number},
+			raw:      "0.5",
+			expected: 0.5,
+		},
+		{
+			name:        "number - invalid",
+			v:           model.Var{Name: "ratio", Type: model.Number},
+			raw:         "half",
+			expectedErr: `"half" is not a number`,
+		},
+		{
+			name:     "list - from flag",
+			v:        model.Var{Name: "deps", Type: model.List},
+			raw:      "github.com/charmbracelet/log, github.com/spf13/cobra",
+			expected: []string{"github.com/charmbracelet/log", "github.com/spf13/cobra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceValue(tt.v, tt.raw)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func Test_ComputeVars(t *testing.T) {
+	vars := model.Vars{
+		{Name: "module_path", Type: model.Computed, Expr: "github.com/{{ author_github_name }}/{{ package_name }}"},
+		{Name: "project_name", Type: model.String},
+		{Name: "package_name", Type: model.Computed, Expr: "{{ project_name | kebab }}"},
+		{Name: "author_github_name", Type: model.String},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		values := map[string]any{"project_name": "My Project", "author_github_name": "bootengine"}
+		err := computeVars(vars[1:], values)
+		td.CmpNoError(t, err)
+		td.Cmp(t, values, map[string]any{
+			"project_name":       "My Project",
+			"author_github_name": "bootengine",
+			"package_name":       "my-project",
+		})
+	})
+
+	t.Run("invalid - relies on a computed var declared later", func(t *testing.T) {
+		values := map[string]any{"project_name": "My Project", "author_github_name": "bootengine"}
+		err := computeVars(vars, values)
+		td.CmpString(t, err, "failed to handle var module_path: undefined vars: package_name")
+	})
+}
+
+func Test_FilterFolderStruct(t *testing.T) {
+	fs := model.FolderStruct{
+		model.Folder{Name: "docker", When: "use_docker", Filers: model.FolderStruct{
+			model.File{Name: "Dockerfile"},
+		}},
+		model.Folder{Name: "web", When: `frontend in ["react", "vue"]`, Filers: model.FolderStruct{
+			model.File{Name: "vite.config.ts", When: `frontend == "vue"`},
+			model.File{Name: "index.html"},
+		}},
+		model.File{Name: "main.go"},
+	}
+
+	got, err := filterFolderStruct(fs, map[string]any{"use_docker": false, "frontend": "react"})
+	td.CmpNoError(t, err)
+	td.Cmp(t, got, model.FolderStruct{
+		model.Folder{Name: "web", When: `frontend in ["react", "vue"]`, Filers: model.FolderStruct{
+			model.File{Name: "index.html"},
+		}},
+		model.File{Name: "main.go"},
+	})
+
+	_, err = filterFolderStruct(fs, map[string]any{"use_docker": false})
+	td.CmpString(t, err, "web: undefined vars: frontend")
+}
+
+func Test_BoundValue(t *testing.T) {
+	values := map[string]any{"project_name": "shop"}
+
+	got, err := boundValue("{{ project_name }}-api", values)
+	td.CmpNoError(t, err)
+	td.Cmp(t, got, "shop-api")
+
+	got, err = boundValue(8080, values)
+	td.CmpNoError(t, err)
+	td.Cmp(t, got, 8080)
+
+	_, err = boundValue("{{ service }}", values)
+	td.CmpString(t, err, "undefined vars: service")
+}
+
+func Test_ScopedValues(t *testing.T) {
+	values := map[string]any{"project_name": "shop", "api.port": 8080, "api.db.name": "orders", "worker.port": 9090}
+
+	td.Cmp(t, scopedValues(values, ""), values)
+	td.Cmp(t, scopedValues(values, "api"), map[string]any{
+		"project_name": "shop",
+		"api.port":     8080,
+		"api.db.name":  "orders",
+		"worker.port":  9090,
+		"port":         8080,
+		"db.name":      "orders",
+	})
+}
+
+func Test_RenderStep(t *testing.T) {
+	values := map[string]any{
+		"author":       "jdoe",
+		"project_name": "demo",
+		"steps":        map[string]any{"go": map[string]any{"outputs": map[string]any{"version": "1.24"}}},
+	}
+	tests := []struct {
+		testname    string
+		step        model.Step
+		dryRun      bool
+		expected    model.Step
+		expectedErr string
+	}{
+		{
+			testname: "rendered",
+			step:     model.Step{Name: "init", CurrentWorkingDir: "{{ project_name }}", Params: []string{"github.com/{{ author }}/{{ project_name }}", "{{ steps.go.outputs.version }}"}},
+			expected: model.Step{Name: "init", CurrentWorkingDir: "demo", Params: []string{"github.com/jdoe/demo", "1.24"}},
+		},
+		{
+			testname:    "undefined output",
+			step:        model.Step{Name: "commit", Params: []string{"{{ steps.commit.outputs.sha }}"}},
+			expectedErr: "undefined vars: steps.commit.outputs.sha",
+		},
+		{
+			testname:    "undefined vars",
+			step:        model.Step{Name: "init {{ org }}", CurrentWorkingDir: "{{ org }}", Params: []string{"{{ project_name }}", "{{ license | upper }}"}},
+			expectedErr: "undefined vars: license, org",
+		},
+		{
+			testname: "dry-run",
+			step:     model.Step{Name: "commit", Params: []string{"{{ steps.commit.outputs.sha }}", "{{ author }}"}},
+			dryRun:   true,
+			expected: model.Step{Name: "commit", Params: []string{"{{ steps.commit.outputs.sha }}", "jdoe"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testname, func(t *testing.T) {
+			got, err := renderStep(tt.step, values, tt.dryRun)
+			if tt.expectedErr != "" {
+				td.CmpString(t, err, tt.expectedErr)
+				return
+			}
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tt.expected)
+		})
+	}
+}
+
+func Test_CheckSteps(t *testing.T) {
+	steps := []model.Step{
+		{Name: "init", Module: "go", Action: model.InitAction, Params: []string{"github.com/{{ author }}/{{ project_name }}"}},
+		{Name: "install", Module: "npm", Action: model.InstallLocalDepsAction, When: "frontend", CurrentWorkingDir: "{{ frontend_dir }}"},
+		{Name: "tag", Module: "git", Action: model.CommitAction, Params: []string{"{{ steps.init.outputs.version }}"}},
+		{Name: "commit {{ project_name }}", Module: "git", Action: model.CommitAction, Params: []string{"{{ message }}", "{{ license }}"}},
+	}
+
+	td.CmpNoError(t, checkSteps(steps, map[string]any{"author": "jdoe", "project_name": "demo", "frontend": false, "message": "init", "license": "mit"}))
+
+	err := checkSteps(steps, map[string]any{"project_name": "demo", "frontend": true, "message": "init"})
+	td.CmpString(t, err, `failed to execute runner for module go, action init: step "init": undefined vars: author
+failed to execute runner for module npm, action installLocalDeps: step "install": undefined vars: frontend_dir
+failed to execute runner for module git, action commit: step "commit {{ project_name }}": undefined vars: license`)
+}
+
+func Test_RenderFolderStruct(t *testing.T) {
+	fs := model.FolderStruct{
+		model.Folder{Name: "{{ project_name }}", Filers: model.FolderStruct{
+			model.File{Name: "{{ project_name | snake }}.go"},
+			model.Folder{Name: "cmd"},
+		}},
+		model.File{Name: "README.md"},
+	}
+
+	got, err := renderFolderStruct(fs, map[string]any{"project_name": "my-app"})
+	td.CmpNoError(t, err)
+	td.Cmp(t, got, model.FolderStruct{
+		model.Folder{Name: "my-app", Filers: model.FolderStruct{
+			model.File{Name: "my_app.go"},
+			model.Folder{Name: "cmd"},
+		}},
+		model.File{Name: "README.md"},
+	})
+	td.Cmp(t, fs[0].GetName(), "{{ project_name }}", "fs is not modified")
+
+	_, err = renderFolderStruct(append(fs, model.File{Name: "{{ author }}.md"}), map[string]any{})
+	td.CmpString(t, err, "undefined vars: author, project_name")
+}
+
+func Test_HandleVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow model.Workflow
+		sets     []string
+		want     map[string]any
+		wantErr  error
+	}{
+		{
+			name: "project name",
+			workflow: model.Workflow{
+				Config: model.Config{CreateRoot: true},
+				Vars:   model.Vars{{Name: "project_name", Type: model.String}},
+			},
+			sets: []string{"project_name=demo"},
+			want: map[string]any{"project_name": "demo"},
+		},
+		{
+			name: "var depending on a disabled var",
+			workflow: model.Workflow{
+				Vars: model.Vars{
+					{Name: "backend", Type: model.Bool},
+					{Name: "use_docker", Type: model.Bool, When: "backend"},
+					{Name: "docker_image", Type: model.String, When: "use_docker", Default: "alpine"},
+					{Name: "registry", Type: model.Computed, Expr: "ghcr.io/{{ docker_image }}", When: "use_docker"},
+				},
+			},
+			sets: []string{"backend=false"},
+			want: map[string]any{"backend": false, "use_docker": nil, "docker_image": nil, "registry": nil},
+		},
+		{
+			name: "var depending on an unset var",
+			workflow: model.Workflow{
+				Vars: model.Vars{
+					{Name: "use_docker", Type: model.Bool},
+					{Name: "docker_image", Type: model.String, When: "!use_docker", Default: "scratch"},
+				},
+			},
+			want: map[string]any{"use_docker": nil, "docker_image": "scratch"},
+		},
+		{
+			name: "missing project name var",
+			workflow: model.Workflow{
+				Config: model.Config{CreateRoot: true},
+				Vars:   model.Vars{{Name: "author", Type: model.String}},
+			},
+			sets:    []string{"author=me"},
+			wantErr: VarError{err: ErrMissingVars, vars: "project_name"},
+		},
+		{
+			name: "unset project name",
+			workflow: model.Workflow{
+				Config: model.Config{CreateRoot: true},
+				Vars:   model.Vars{{Name: "project_name", Type: model.String}},
+			},
+			wantErr: VarError{err: ErrMissingVars, vars: "project_name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(context.Background(), nil, tt.workflow, Options{DryRun: true, NoInput: true, Sets: tt.sets})
+			err := r.handleVars()
+			if tt.wantErr != nil {
+				td.Cmp(t, err, tt.wantErr)
+				return
+			}
+			td.Require(t).CmpNoError(err)
+			td.Cmp(t, r.ctx.Value(helper.ValueKey{}), tt.want)
+		})
+	}
+}